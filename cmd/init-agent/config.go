@@ -0,0 +1,248 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/kcp-dev/init-agent/internal/log"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	configAPIVersion = "config.initialization.kcp.io/v1alpha1"
+	configKind       = "InitAgentConfiguration"
+)
+
+// Configuration is the format of the file given via --config. All fields are
+// optional and correspond to the command line flag mentioned in their
+// comment. Flags that are given on the command line take precedence over the
+// file, which in turn takes precedence over the defaults.
+type Configuration struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// --config-workspace
+	ConfigWorkspace *string `json:"configWorkspace,omitempty"`
+	// --additional-config-workspaces
+	AdditionalConfigWorkspaces []string `json:"additionalConfigWorkspaces,omitempty"`
+	// --apiexport-endpointslice
+	APIExportEndpointSlice *string `json:"apiExportEndpointSlice,omitempty"`
+	// --init-target-selector
+	InitTargetSelector *string `json:"initTargetSelector,omitempty"`
+	// --kind-order
+	KindOrder []string `json:"kindOrder,omitempty"`
+	// --continue-on-error
+	ContinueOnError *bool `json:"continueOnError,omitempty"`
+	// --api-wait-timeout
+	APIWaitTimeout *metav1.Duration `json:"apiWaitTimeout,omitempty"`
+	// --metrics-address
+	MetricsAddress *string `json:"metricsAddress,omitempty"`
+	// --health-address
+	HealthAddress *string `json:"healthAddress,omitempty"`
+
+	Log            LogConfiguration            `json:"log,omitempty"`
+	Concurrency    ConcurrencyConfiguration    `json:"concurrency,omitempty"`
+	Retry          RetryConfiguration          `json:"retry,omitempty"`
+	Discovery      DiscoveryConfiguration      `json:"discovery,omitempty"`
+	LeaderElection LeaderElectionConfiguration `json:"leaderElection,omitempty"`
+	Sharding       ShardingConfiguration       `json:"sharding,omitempty"`
+	Tracing        TracingConfiguration        `json:"tracing,omitempty"`
+	Provenance     ProvenanceConfiguration     `json:"provenance,omitempty"`
+}
+
+type LogConfiguration struct {
+	// --log-debug
+	Debug *bool `json:"debug,omitempty"`
+	// --log-format
+	Format *string `json:"format,omitempty"`
+}
+
+type ConcurrencyConfiguration struct {
+	// --init-workers
+	InitWorkers *int `json:"initWorkers,omitempty"`
+	// --max-concurrent-initializations
+	MaxConcurrentInitializations *int `json:"maxConcurrentInitializations,omitempty"`
+	// --client-qps
+	ClientQPS *float32 `json:"clientQPS,omitempty"`
+	// --client-burst
+	ClientBurst *int `json:"clientBurst,omitempty"`
+}
+
+type RetryConfiguration struct {
+	// --retry-initial-delay
+	InitialDelay *metav1.Duration `json:"initialDelay,omitempty"`
+	// --retry-max-delay
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+}
+
+type DiscoveryConfiguration struct {
+	// --discovery-workspaces
+	Workspaces []string `json:"workspaces,omitempty"`
+	// --discovery-interval
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// --discovery-labels
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type LeaderElectionConfiguration struct {
+	// --enable-leader-election
+	Enabled *bool `json:"enabled,omitempty"`
+	// --leader-election-id
+	ID *string `json:"id,omitempty"`
+	// --leader-election-namespace
+	Namespace *string `json:"namespace,omitempty"`
+	// --leader-election-lease-duration
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
+	// --leader-election-renew-deadline
+	RenewDeadline *metav1.Duration `json:"renewDeadline,omitempty"`
+	// --leader-election-retry-period
+	RetryPeriod *metav1.Duration `json:"retryPeriod,omitempty"`
+}
+
+type ShardingConfiguration struct {
+	// --enable-sharding
+	Enabled *bool `json:"enabled,omitempty"`
+	// --shard-group
+	Group *string `json:"group,omitempty"`
+	// --shard-identity
+	Identity *string `json:"identity,omitempty"`
+	// --shard-namespace
+	Namespace *string `json:"namespace,omitempty"`
+	// --shard-lease-duration
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
+}
+
+type TracingConfiguration struct {
+	// --tracing-otlp-endpoint
+	OTLPEndpoint *string `json:"otlpEndpoint,omitempty"`
+	// --tracing-sampling-ratio
+	SamplingRatio *float64 `json:"samplingRatio,omitempty"`
+}
+
+type ProvenanceConfiguration struct {
+	// --enable-provenance
+	Enabled *bool `json:"enabled,omitempty"`
+	// --managed-by
+	ManagedBy *string `json:"managedBy,omitempty"`
+}
+
+// loadConfiguration reads and parses a configuration file. Unknown fields are
+// rejected to catch typos.
+func loadConfiguration(filename string) (*Configuration, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseConfiguration(content)
+}
+
+func parseConfiguration(content []byte) (*Configuration, error) {
+	cfg := &Configuration{}
+	if err := yaml.UnmarshalStrict(content, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+
+	if cfg.APIVersion != configAPIVersion || cfg.Kind != configKind {
+		return nil, fmt.Errorf("unsupported configuration %s/%s, expected %s/%s", cfg.APIVersion, cfg.Kind, configAPIVersion, configKind)
+	}
+
+	return cfg, nil
+}
+
+// apply sets all options that are configured in the file, unless the
+// corresponding flag was given on the command line.
+func (c *Configuration) apply(o *Options, flags *pflag.FlagSet) error {
+	override(flags, "config-workspace", &o.ConfigWorkspace, c.ConfigWorkspace)
+	overrideSlice(flags, "additional-config-workspaces", &o.AdditionalConfigWorkspaces, c.AdditionalConfigWorkspaces)
+	override(flags, "apiexport-endpointslice", &o.APIExportEndpointSlice, c.APIExportEndpointSlice)
+	override(flags, "init-target-selector", &o.InitTargetSelectorString, c.InitTargetSelector)
+	overrideSlice(flags, "kind-order", &o.KindOrder, c.KindOrder)
+	override(flags, "continue-on-error", &o.ContinueOnError, c.ContinueOnError)
+	overrideDuration(flags, "api-wait-timeout", &o.APIWaitTimeout, c.APIWaitTimeout)
+	override(flags, "metrics-address", &o.MetricsAddr, c.MetricsAddress)
+	override(flags, "health-address", &o.HealthAddr, c.HealthAddress)
+
+	override(flags, "log-debug", &o.LogOptions.Debug, c.Log.Debug)
+	if c.Log.Format != nil && !flags.Changed("log-format") {
+		var format log.Format
+		if err := format.Set(*c.Log.Format); err != nil {
+			return fmt.Errorf("invalid log.format: %w", err)
+		}
+		o.LogOptions.Format = format
+	}
+
+	override(flags, "init-workers", &o.InitWorkers, c.Concurrency.InitWorkers)
+	override(flags, "max-concurrent-initializations", &o.MaxConcurrentInitializations, c.Concurrency.MaxConcurrentInitializations)
+	override(flags, "client-qps", &o.ClientQPS, c.Concurrency.ClientQPS)
+	override(flags, "client-burst", &o.ClientBurst, c.Concurrency.ClientBurst)
+
+	overrideDuration(flags, "retry-initial-delay", &o.RetryInitialDelay, c.Retry.InitialDelay)
+	overrideDuration(flags, "retry-max-delay", &o.RetryMaxDelay, c.Retry.MaxDelay)
+
+	overrideSlice(flags, "discovery-workspaces", &o.DiscoveryWorkspaces, c.Discovery.Workspaces)
+	overrideDuration(flags, "discovery-interval", &o.DiscoveryInterval, c.Discovery.Interval)
+	if c.Discovery.Labels != nil && !flags.Changed("discovery-labels") {
+		o.DiscoveryLabels = c.Discovery.Labels
+	}
+
+	override(flags, "enable-leader-election", &o.EnableLeaderElection, c.LeaderElection.Enabled)
+	override(flags, "leader-election-id", &o.LeaderElectionID, c.LeaderElection.ID)
+	override(flags, "leader-election-namespace", &o.LeaderElectionNamespace, c.LeaderElection.Namespace)
+	overrideDuration(flags, "leader-election-lease-duration", &o.LeaderElectionLeaseDuration, c.LeaderElection.LeaseDuration)
+	overrideDuration(flags, "leader-election-renew-deadline", &o.LeaderElectionRenewDeadline, c.LeaderElection.RenewDeadline)
+	overrideDuration(flags, "leader-election-retry-period", &o.LeaderElectionRetryPeriod, c.LeaderElection.RetryPeriod)
+
+	override(flags, "enable-sharding", &o.EnableSharding, c.Sharding.Enabled)
+	override(flags, "shard-group", &o.ShardGroup, c.Sharding.Group)
+	override(flags, "shard-identity", &o.ShardIdentity, c.Sharding.Identity)
+	override(flags, "shard-namespace", &o.ShardNamespace, c.Sharding.Namespace)
+	overrideDuration(flags, "shard-lease-duration", &o.ShardLeaseDuration, c.Sharding.LeaseDuration)
+
+	override(flags, "tracing-otlp-endpoint", &o.TracingOptions.OTLPEndpoint, c.Tracing.OTLPEndpoint)
+	override(flags, "tracing-sampling-ratio", &o.TracingOptions.SamplingRatio, c.Tracing.SamplingRatio)
+
+	override(flags, "enable-provenance", &o.EnableProvenance, c.Provenance.Enabled)
+	override(flags, "managed-by", &o.ManagedBy, c.Provenance.ManagedBy)
+
+	return nil
+}
+
+func override[T any](flags *pflag.FlagSet, flag string, dst *T, value *T) {
+	if value != nil && !flags.Changed(flag) {
+		*dst = *value
+	}
+}
+
+func overrideSlice[T any](flags *pflag.FlagSet, flag string, dst *[]T, value []T) {
+	if value != nil && !flags.Changed(flag) {
+		*dst = value
+	}
+}
+
+func overrideDuration(flags *pflag.FlagSet, flag string, dst *time.Duration, value *metav1.Duration) {
+	if value != nil && !flags.Changed(flag) {
+		*dst = value.Duration
+	}
+}
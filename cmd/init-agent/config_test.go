@@ -0,0 +1,227 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/kcp-dev/init-agent/internal/log"
+)
+
+const testConfig = `
+apiVersion: config.initialization.kcp.io/v1alpha1
+kind: InitAgentConfiguration
+configWorkspace: root:init-agent
+initTargetSelector: env=prod
+log:
+  debug: true
+  format: console
+concurrency:
+  initWorkers: 8
+retry:
+  initialDelay: 10s
+discovery:
+  workspaces: [root:a, root:b]
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write configuration: %v", err)
+	}
+
+	return filename
+}
+
+func parseFlags(t *testing.T, args ...string) (Options, *pflag.FlagSet) {
+	t.Helper()
+
+	opts := NewOptions()
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	return *opts, flags
+}
+
+func TestLoadOptionsPrecedence(t *testing.T) {
+	filename := writeConfig(t, testConfig)
+	base, flags := parseFlags(t, "--config", filename, "--init-workers", "2", "--retry-max-delay", "1m")
+
+	opts, err := loadOptions(base, flags)
+	if err != nil {
+		t.Fatalf("Failed to load options: %v", err)
+	}
+
+	// from the file
+	if opts.ConfigWorkspace != "root:init-agent" {
+		t.Errorf("Expected config workspace from file, got %q.", opts.ConfigWorkspace)
+	}
+
+	if s := opts.InitTargetSelector.String(); s != "env=prod" {
+		t.Errorf("Expected selector from file, got %q.", s)
+	}
+
+	if !opts.LogOptions.Debug || opts.LogOptions.Format != log.FormatConsole {
+		t.Errorf("Expected log options from file, got %+v.", opts.LogOptions)
+	}
+
+	if opts.RetryInitialDelay != 10*time.Second {
+		t.Errorf("Expected retry initial delay from file, got %v.", opts.RetryInitialDelay)
+	}
+
+	if !reflect.DeepEqual(opts.DiscoveryWorkspaces, []string{"root:a", "root:b"}) {
+		t.Errorf("Expected discovery workspaces from file, got %v.", opts.DiscoveryWorkspaces)
+	}
+
+	// flags win over the file
+	if opts.InitWorkers != 2 {
+		t.Errorf("Expected init workers from flags, got %d.", opts.InitWorkers)
+	}
+
+	if opts.RetryMaxDelay != time.Minute {
+		t.Errorf("Expected retry max delay from flags, got %v.", opts.RetryMaxDelay)
+	}
+
+	// defaults for everything else
+	if opts.ClientQPS != 20 {
+		t.Errorf("Expected default client QPS, got %v.", opts.ClientQPS)
+	}
+}
+
+func TestLoadOptionsInvalid(t *testing.T) {
+	testcases := map[string]string{
+		"unknown field": `
+apiVersion: config.initialization.kcp.io/v1alpha1
+kind: InitAgentConfiguration
+configWorkspace: root
+initWorkers: 3
+`,
+		"wrong kind": `
+apiVersion: config.initialization.kcp.io/v1alpha1
+kind: Something
+configWorkspace: root
+`,
+		"failed validation": `
+apiVersion: config.initialization.kcp.io/v1alpha1
+kind: InitAgentConfiguration
+configWorkspace: root
+concurrency:
+  initWorkers: 0
+`,
+		"invalid log format": `
+apiVersion: config.initialization.kcp.io/v1alpha1
+kind: InitAgentConfiguration
+configWorkspace: root
+log:
+  format: xml
+`,
+		"duplicate config workspace": `
+apiVersion: config.initialization.kcp.io/v1alpha1
+kind: InitAgentConfiguration
+configWorkspace: root
+additionalConfigWorkspaces:
+- root:team-a
+- root
+`,
+		"invalid managed-by": `
+apiVersion: config.initialization.kcp.io/v1alpha1
+kind: InitAgentConfiguration
+configWorkspace: root
+provenance:
+  managedBy: not a label value
+`,
+	}
+
+	for name, content := range testcases {
+		t.Run(name, func(t *testing.T) {
+			base, flags := parseFlags(t, "--config", writeConfig(t, content))
+
+			if _, err := loadOptions(base, flags); err == nil {
+				t.Fatal("Expected an error, but got none.")
+			}
+		})
+	}
+}
+
+func TestReloadOnlyAppliesReloadableOptions(t *testing.T) {
+	filename := writeConfig(t, testConfig)
+	base, flags := parseFlags(t, "--config", filename)
+
+	current, err := loadOptions(base, flags)
+	if err != nil {
+		t.Fatalf("Failed to load options: %v", err)
+	}
+
+	var applied *Options
+	reloader := newConfigReloader(log.NewDefault().Sugar(), base, flags, current, func(opts *Options) {
+		applied = opts
+	})
+
+	updated := `
+apiVersion: config.initialization.kcp.io/v1alpha1
+kind: InitAgentConfiguration
+configWorkspace: root:elsewhere
+initTargetSelector: env=staging
+concurrency:
+  initWorkers: 16
+`
+	if err := os.WriteFile(filename, []byte(updated), 0o600); err != nil {
+		t.Fatalf("Failed to write configuration: %v", err)
+	}
+
+	reloader.reload(false)
+
+	if applied == nil {
+		t.Fatal("Expected configuration to be applied.")
+	}
+
+	if applied.InitWorkers != 16 || applied.InitTargetSelector.String() != "env=staging" {
+		t.Errorf("Expected reloadable options to be updated, got %d workers and selector %q.", applied.InitWorkers, applied.InitTargetSelector)
+	}
+
+	if applied.LogOptions.Debug {
+		t.Error("Expected debug logging to be disabled after it was removed from the file.")
+	}
+
+	if applied.ConfigWorkspace != "root:init-agent" {
+		t.Errorf("Expected config workspace to require a restart, but it was changed to %q.", applied.ConfigWorkspace)
+	}
+
+	// an invalid file keeps the current configuration
+	applied = nil
+	if err := os.WriteFile(filename, []byte("kind: nonsense"), 0o600); err != nil {
+		t.Fatalf("Failed to write configuration: %v", err)
+	}
+
+	reloader.reload(false)
+
+	if applied != nil {
+		t.Fatal("Expected invalid configuration not to be applied.")
+	}
+}
@@ -18,14 +18,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	golog "log"
+	"net/http"
 
 	"github.com/go-logr/zapr"
 	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 
+	"github.com/kcp-dev/init-agent/internal/controller/discoverycontroller"
 	"github.com/kcp-dev/init-agent/internal/controller/initcontroller"
 	"github.com/kcp-dev/init-agent/internal/controller/targetcontroller"
 	"github.com/kcp-dev/init-agent/internal/initialize/source"
@@ -33,29 +36,35 @@ import (
 	"github.com/kcp-dev/init-agent/internal/kcp"
 	syncagentlog "github.com/kcp-dev/init-agent/internal/log"
 	"github.com/kcp-dev/init-agent/internal/manifest"
+	"github.com/kcp-dev/init-agent/internal/settings"
+	"github.com/kcp-dev/init-agent/internal/sharding"
+	"github.com/kcp-dev/init-agent/internal/tracing"
 	"github.com/kcp-dev/init-agent/internal/version"
 	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
 
 	"github.com/kcp-dev/logicalcluster/v3"
+	"github.com/kcp-dev/multicluster-provider/apiexport"
+	kcpapisv1alpha1 "github.com/kcp-dev/sdk/apis/apis/v1alpha1"
 	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
 	ctrlruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	ctrlruntimelog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	mcmanager "sigs.k8s.io/multicluster-runtime/pkg/manager"
 )
 
-const (
-	// numInitWorkers is the number of parallel works in the initcontroller.
-	numInitWorkers = 4
-)
-
 func main() {
-	ctx := context.Background()
+	// SIGTERM and SIGINT cancel the context, so that the manager can stop all
+	// controllers, release the leader Lease, leave the shard group and flush
+	// traces before the process exits.
+	ctx := ctrlruntime.SetupSignalHandler()
 
 	opts := NewOptions()
 	opts.AddFlags(pflag.CommandLine)
@@ -64,48 +73,90 @@ func main() {
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
 
-	if err := opts.Validate(); err != nil {
-		golog.Fatalf("Invalid command line: %v", err)
-	}
-
-	log := syncagentlog.NewFromOptions(opts.LogOptions)
+	// keep the options from the command line, so that the configuration file
+	// can be re-applied on top of them when it is reloaded
+	cliOpts := *opts
 
-	if err := opts.Complete(); err != nil {
-		log.With(zap.Error(err)).Fatal("Invalid command line")
+	opts, err := loadOptions(cliOpts, pflag.CommandLine)
+	if err != nil {
+		golog.Fatalf("Invalid configuration: %v", err)
 	}
 
+	// the log level can be changed when the configuration is reloaded
+	logLevel := zap.NewAtomicLevelAt(syncagentlog.Level(opts.LogOptions.Debug))
+	log := syncagentlog.NewWithLevel(logLevel, opts.LogOptions.Format)
 	sugar := log.Sugar()
 
 	// set the logger used by sigs.k8s.io/controller-runtime
 	ctrlruntimelog.SetLogger(zapr.NewLogger(log.WithOptions(zap.AddCallerSkip(1))))
 
-	if err := run(ctx, sugar, opts); err != nil {
+	if err := run(ctx, sugar, logLevel, cliOpts, opts); err != nil {
 		sugar.Fatalw("Init Agent has encountered an error", zap.Error(err))
 	}
 }
 
-func run(ctx context.Context, log *zap.SugaredLogger, opts *Options) error {
+func run(ctx context.Context, log *zap.SugaredLogger, logLevel zap.AtomicLevel, cliOpts Options, opts *Options) error {
 	hello := log.With(
 		"version", version.GitVersion,
 		"configws", opts.ConfigWorkspace,
+		"additionalconfigws", opts.AdditionalConfigWorkspaces,
 		"targetselector", opts.InitTargetSelector.String(),
 	)
 
 	hello.Info("Hei, I'm the kcp Init Agent")
 
-	cfg := ctrlruntime.GetConfigOrDie()
-	clusterClient := kcp.NewClusterClient(kcp.StripCluster(cfg))
+	shutdownTracing, err := tracing.Setup(ctx, opts.TracingOptions)
+	if err != nil {
+		return fmt.Errorf("failed to setup tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Warnw("Failed to flush traces", zap.Error(err))
+		}
+	}()
+
+	// propagate the trace context to kcp with every request
+	cfg := tracing.WrapConfig(ctrlruntime.GetConfigOrDie())
+
+	// All requests to workspaces (but not those for leader election or sharding
+	// in the config workspace) share a single rate limiter, so that creation
+	// storms cannot overload kcp, no matter how many init controllers run.
+	workspaceCfg := rest.CopyConfig(cfg)
+	if opts.ClientQPS > 0 {
+		workspaceCfg.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(opts.ClientQPS, opts.ClientBurst)
+	} else {
+		workspaceCfg.QPS = -1
+	}
+
+	clusterClient := kcp.NewClusterClient(kcp.StripCluster(workspaceCfg))
+
+	// Optionally let tenants manage their own InitTargets in all workspaces
+	// that bind the init-agent's APIExport.
+	var tenants targetcontroller.TenantProvider
+	if opts.APIExportEndpointSlice != "" {
+		provider, err := setupTenantProvider(cfg, opts, log)
+		if err != nil {
+			return fmt.Errorf("failed to setup tenant provider: %w", err)
+		}
+
+		tenants = provider
+	}
 
 	// prepare the source factory, responsible for resolving and instantiating all
 	// possible init sources of an InitTarget
-	sourceFactory, err := setupSourceFactory(clusterClient)
+	sourceFactory, err := setupSourceFactory(clusterClient, tenants)
 	if err != nil {
 		return fmt.Errorf("failed to setup source factory: %w", err)
 	}
 
 	// manifestApplier controls how the manifests of an init source are applied in
 	// the target workspace
-	manifestApplier := manifest.NewApplier()
+	kindSorter, err := manifest.NewSorter(opts.KindOrder)
+	if err != nil {
+		return fmt.Errorf("failed to setup object sorter: %w", err)
+	}
+
+	manifestApplier := manifest.NewApplier(kindSorter)
 
 	// create the ctrl-runtime manager
 	mgr, err := setupManager(ctx, cfg, opts)
@@ -113,19 +164,88 @@ func run(ctx context.Context, log *zap.SugaredLogger, opts *Options) error {
 		return fmt.Errorf("failed to setup local manager: %w", err)
 	}
 
+	// In sharding mode, every replica runs all init controllers, but only
+	// processes its own share of the workspaces.
+	var (
+		shardFilter          initcontroller.ShardFilter
+		discoveryShardFilter discoverycontroller.ShardFilter
+	)
+	if opts.EnableSharding {
+		membership := sharding.NewMembership(mgr.GetClient(), mgr.GetAPIReader(), log, sharding.Options{
+			Namespace:     opts.ShardNamespace,
+			Group:         opts.ShardGroup,
+			Identity:      opts.ShardIdentity,
+			LeaseDuration: opts.ShardLeaseDuration,
+		})
+
+		if err := mgr.Add(membership); err != nil {
+			return fmt.Errorf("failed to add shard membership: %w", err)
+		}
+
+		shardFilter = membership
+		discoveryShardFilter = membership
+	}
+
+	// limit the number of concurrent initializations across all InitTargets
+	limiter := initcontroller.NewLimiter(opts.MaxConcurrentInitializations)
+
+	// settings that can be changed by reloading the configuration file
+	settingsStore := settings.NewStore(opts.Settings())
+
+	if opts.ConfigFile != "" {
+		reloader := newConfigReloader(log, cliOpts, pflag.CommandLine, opts, func(updated *Options) {
+			logLevel.SetLevel(syncagentlog.Level(updated.LogOptions.Debug))
+			limiter.SetLimit(updated.MaxConcurrentInitializations)
+			settingsStore.Set(updated.Settings())
+		})
+
+		if err := mgr.Add(reloader); err != nil {
+			return fmt.Errorf("failed to add configuration reloader: %w", err)
+		}
+	}
+
 	// This controller watches InitTargets and spawns multicluster-managers for each of them,
 	// which in turn run the actual business logic controllers.
 
 	// wrap this controller creation in a closure to prevent giving all the initcontroller
 	// dependencies to the targetcontroller
-	newInitController := func(remoteManager mcmanager.Manager, targetProvider initcontroller.InitTargetProvider, initializer kcpcorev1alpha1.LogicalClusterInitializer) error {
-		return initcontroller.Create(remoteManager, targetProvider, sourceFactory, manifestApplier, initializer, log, numInitWorkers)
+	newInitController := func(remoteManager mcmanager.Manager, initTarget string, targetProvider initcontroller.InitTargetProvider, reporter initcontroller.ResultReporter, initializer kcpcorev1alpha1.LogicalClusterInitializer, workers int) error {
+		return initcontroller.Create(remoteManager, targetProvider, reporter, sourceFactory, manifestApplier, initializer, log, initcontroller.Options{
+			InitTarget:      initTarget,
+			NumWorkers:      workers,
+			ContinueOnError: opts.ContinueOnError,
+			APIWaitTimeout:  opts.APIWaitTimeout,
+			Provenance:      opts.EnableProvenance,
+			ManagedBy:       opts.ManagedBy,
+			ShardFilter:     shardFilter,
+			Limiter:         limiter,
+			Settings:        settingsStore,
+		})
+	}
+
+	configWorkspaces := targetcontroller.Workspaces{
+		Primary:    opts.ConfigWorkspace,
+		Additional: opts.AdditionalConfigWorkspaces,
+		Tenants:    tenants,
 	}
 
-	if err := targetcontroller.Add(ctx, mgr, log, opts.InitTargetSelector, clusterClient, newInitController); err != nil {
+	if err := targetcontroller.Add(mgr, log, settingsStore, clusterClient, configWorkspaces, newInitController); err != nil {
 		return fmt.Errorf("failed to add targetcontroller controller: %w", err)
 	}
 
+	// Optionally manage InitTargets for all annotated WorkspaceTypes, which are
+	// then picked up by the targetcontroller like any other InitTarget.
+	if len(opts.DiscoveryWorkspaces) > 0 {
+		if err := discoverycontroller.Add(mgr, log, clusterClient, discoverycontroller.Options{
+			Workspaces:  opts.DiscoveryWorkspaces,
+			Interval:    opts.DiscoveryInterval,
+			Labels:      opts.DiscoveryLabels,
+			ShardFilter: discoveryShardFilter,
+		}); err != nil {
+			return fmt.Errorf("failed to add discovery controller: %w", err)
+		}
+	}
+
 	log.Info("Starting kcp Init Agent…")
 
 	return mgr.Start(ctx)
@@ -141,31 +261,84 @@ func setupManager(ctx context.Context, cfg *rest.Config, opts *Options) (manager
 		return nil, fmt.Errorf("failed to register local scheme %s: %w", corev1.SchemeGroupVersion, err)
 	}
 
+	if err := coordinationv1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to register local scheme %s: %w", coordinationv1.SchemeGroupVersion, err)
+	}
+
 	if err := initializationv1alpha1.AddToScheme(scheme); err != nil {
 		return nil, fmt.Errorf("failed to register local scheme %s: %w", initializationv1alpha1.SchemeGroupVersion, err)
 	}
 
 	cfg = kcp.RetargetRestConfig(cfg, logicalcluster.Name(opts.ConfigWorkspace))
 
-	return manager.New(cfg, manager.Options{
+	mgr, err := manager.New(cfg, manager.Options{
 		Scheme: scheme,
 		BaseContext: func() context.Context {
 			return ctx
 		},
-		Metrics:                 metricsserver.Options{BindAddress: opts.MetricsAddr},
-		LeaderElection:          opts.EnableLeaderElection,
-		LeaderElectionID:        "TODO",
-		LeaderElectionNamespace: "le-ns-todo",
-		HealthProbeBindAddress:  opts.HealthAddr,
+		Metrics:                       metricsserver.Options{BindAddress: opts.MetricsAddr},
+		LeaderElection:                opts.EnableLeaderElection,
+		LeaderElectionID:              opts.LeaderElectionID,
+		LeaderElectionNamespace:       opts.LeaderElectionNamespace,
+		LeaseDuration:                 &opts.LeaderElectionLeaseDuration,
+		RenewDeadline:                 &opts.LeaderElectionRenewDeadline,
+		RetryPeriod:                   &opts.LeaderElectionRetryPeriod,
+		LeaderElectionReleaseOnCancel: true,
+		HealthProbeBindAddress:        opts.HealthAddr,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		return nil, fmt.Errorf("failed to add healthz check: %w", err)
+	}
+
+	// Only the leader is ready, so that it's obvious which replica is doing
+	// the work. Without leader election, the agent is always the leader.
+	if err := mgr.AddReadyzCheck("leader", func(_ *http.Request) error {
+		select {
+		case <-mgr.Elected():
+			return nil
+		default:
+			return errors.New("not the leader")
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("failed to add readyz check: %w", err)
+	}
+
+	return mgr, nil
 }
 
-func setupSourceFactory(clusterClient kcp.ClusterClient) (*source.Factory, error) {
+func setupSourceFactory(clusterClient kcp.ClusterClient, tenants targetcontroller.TenantProvider) (*source.Factory, error) {
 	deps := source.Dependencies{
 		Template: inittemplate.Dependencies{
 			ClusterClient: clusterClient,
+			Tenants:       tenants,
 		},
 	}
 
 	return source.NewFactory(deps), nil
 }
+
+// setupTenantProvider creates a provider for all workspaces that bind the
+// init-agent's APIExport. The APIExportEndpointSlice must live in the config
+// workspace.
+func setupTenantProvider(cfg *rest.Config, opts *Options, log *zap.SugaredLogger) (*apiexport.Provider, error) {
+	scheme := runtime.NewScheme()
+
+	if err := kcpapisv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to register local scheme %s: %w", kcpapisv1alpha1.SchemeGroupVersion, err)
+	}
+
+	if err := initializationv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to register local scheme %s: %w", initializationv1alpha1.SchemeGroupVersion, err)
+	}
+
+	logger := zapr.NewLogger(log.Named("tenants").Desugar())
+
+	return apiexport.New(kcp.RetargetRestConfig(cfg, logicalcluster.Name(opts.ConfigWorkspace)), opts.APIExportEndpointSlice, apiexport.Options{
+		Scheme: scheme,
+		Log:    &logger,
+	})
+}
@@ -19,13 +19,22 @@ package main
 import (
 	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 
 	"github.com/kcp-dev/init-agent/internal/log"
+	"github.com/kcp-dev/init-agent/internal/manifest"
+	"github.com/kcp-dev/init-agent/internal/settings"
+	"github.com/kcp-dev/init-agent/internal/tracing"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
 
 	"k8s.io/apimachinery/pkg/labels"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type Options struct {
@@ -34,20 +43,112 @@ type Options struct {
 	// work.
 	// KubeconfigFile string
 
+	// ConfigFile is an optional YAML file with further options (see
+	// Configuration). Some of them are reloaded when the file changes.
+	ConfigFile string
+
 	// ConfigWorkspace is the kcp workspace (either a path or a cluster name)
 	// where the InitTarget and InitTemplate objects live that should be processed
 	// by this init-agent.
 	ConfigWorkspace string
 
+	// AdditionalConfigWorkspaces are further workspaces in which InitTargets
+	// are processed. Leader election, sharding and discovery only ever use the
+	// ConfigWorkspace.
+	AdditionalConfigWorkspaces []string
+
+	// APIExportEndpointSlice is the name of an APIExportEndpointSlice in the
+	// ConfigWorkspace. If set, tenants can manage their own InitTargets and
+	// InitTemplates in all workspaces that bind the corresponding APIExport.
+	APIExportEndpointSlice string
+
 	// Whether or not to perform leader election (requires permissions to
 	// manage coordination/v1 leases)
 	EnableLeaderElection bool
 
+	// LeaderElectionID is the name of the Lease object in the config workspace.
+	LeaderElectionID string
+
+	// LeaderElectionNamespace is the namespace in the config workspace where
+	// the Lease object is created.
+	LeaderElectionNamespace string
+
+	LeaderElectionLeaseDuration time.Duration
+	LeaderElectionRenewDeadline time.Duration
+	LeaderElectionRetryPeriod   time.Duration
+
+	// EnableSharding makes multiple replicas split the workspaces between
+	// them instead of electing a single leader.
+	EnableSharding bool
+
+	// ShardGroup is the name shared by all replicas that split the work.
+	ShardGroup string
+
+	// ShardIdentity is the unique name of this replica within the group.
+	ShardIdentity string
+
+	// ShardNamespace is the namespace in the config workspace where each
+	// replica maintains its Lease.
+	ShardNamespace string
+
+	ShardLeaseDuration time.Duration
+
 	InitTargetSelectorString string
 	InitTargetSelector       labels.Selector
 
+	// DiscoveryWorkspaces are the workspaces in which annotated WorkspaceTypes
+	// are discovered to automatically manage InitTargets for them. Discovery
+	// is disabled if empty.
+	DiscoveryWorkspaces []string
+
+	// DiscoveryInterval is how often the discovery workspaces are scanned.
+	DiscoveryInterval time.Duration
+
+	// DiscoveryLabels are placed on all discovered InitTargets.
+	DiscoveryLabels map[string]string
+
+	// KindOrder is the default priority table used to sort objects before
+	// applying them. InitTargets can override this.
+	KindOrder []string
+
+	// ContinueOnError makes the agent attempt to apply all objects, even if
+	// some of them fail, instead of stopping at the first failing object.
+	ContinueOnError bool
+
+	// EnableProvenance makes the agent record the InitTarget, init source and
+	// InitTemplate as labels and annotations on every object it creates.
+	EnableProvenance bool
+
+	// ManagedBy is the value of the app.kubernetes.io/managed-by label on
+	// created objects. The label is omitted if this is empty.
+	ManagedBy string
+
+	// InitWorkers is the default number of workspaces per InitTarget that are
+	// initialized in parallel. InitTargets can override this.
+	InitWorkers int
+
+	// MaxConcurrentInitializations limits the number of workspaces that are
+	// initialized in parallel across all InitTargets. Zero means no limit.
+	MaxConcurrentInitializations int
+
+	// ClientQPS and ClientBurst configure the rate limiter that is shared by
+	// all requests to workspaces. A QPS of zero disables rate limiting.
+	ClientQPS   float32
+	ClientBurst int
+
+	// RetryInitialDelay and RetryMaxDelay are the default delays between
+	// initialization attempts. InitTargets can override them.
+	RetryInitialDelay time.Duration
+	RetryMaxDelay     time.Duration
+
+	// APIWaitTimeout is how long to wait for a missing API (e.g. a CRD or an
+	// APIBinding provided by another party) before treating it as an error.
+	APIWaitTimeout time.Duration
+
 	LogOptions log.Options
 
+	TracingOptions tracing.Options
+
 	MetricsAddr string
 	HealthAddr  string
 }
@@ -55,17 +156,68 @@ type Options struct {
 func NewOptions() *Options {
 	return &Options{
 		LogOptions:         log.NewDefaultOptions(),
+		TracingOptions:     tracing.Options{SamplingRatio: 1},
 		InitTargetSelector: labels.Everything(),
+		KindOrder:          manifest.DefaultKindOrder,
+		EnableProvenance:   true,
+		ManagedBy:          initializationv1alpha1.ManagedByValue,
+		APIWaitTimeout:     10 * time.Minute,
+		DiscoveryInterval:  30 * time.Second,
+		InitWorkers:        4,
+		ClientQPS:          20,
+		ClientBurst:        30,
+		RetryInitialDelay:  5 * time.Second,
+		RetryMaxDelay:      5 * time.Minute,
 		MetricsAddr:        "127.0.0.1:8085",
+
+		LeaderElectionID:            "kcp-init-agent",
+		LeaderElectionNamespace:     "default",
+		LeaderElectionLeaseDuration: 15 * time.Second,
+		LeaderElectionRenewDeadline: 10 * time.Second,
+		LeaderElectionRetryPeriod:   2 * time.Second,
+
+		ShardGroup:         "kcp-init-agent",
+		ShardIdentity:      defaultShardIdentity(),
+		ShardNamespace:     "default",
+		ShardLeaseDuration: 30 * time.Second,
 	}
 }
 
 func (o *Options) AddFlags(flags *pflag.FlagSet) {
 	o.LogOptions.AddPFlags(flags)
 
+	flags.StringVar(&o.ConfigFile, "config", o.ConfigFile, "path to a YAML configuration file; flags given on the command line take precedence (optional)")
 	flags.StringVar(&o.ConfigWorkspace, "config-workspace", o.ConfigWorkspace, "kcp workspace or cluster where the InitTargets live that should be processed")
+	flags.StringSliceVar(&o.AdditionalConfigWorkspaces, "additional-config-workspaces", o.AdditionalConfigWorkspaces, "comma-separated list of further kcp workspaces or clusters where InitTargets live that should be processed (optional)")
+	flags.StringVar(&o.APIExportEndpointSlice, "apiexport-endpointslice", o.APIExportEndpointSlice, "name of the APIExportEndpointSlice in the config workspace through which InitTargets of tenants are processed (optional)")
 	flags.StringVar(&o.InitTargetSelectorString, "init-target-selector", o.InitTargetSelectorString, "restrict to only process InitTargets matching this label selector (optional)")
+	flags.StringSliceVar(&o.DiscoveryWorkspaces, "discovery-workspaces", o.DiscoveryWorkspaces, "comma-separated list of workspaces in which WorkspaceTypes annotated with initialization.kcp.io/templates are discovered to automatically create InitTargets for them (optional)")
+	flags.DurationVar(&o.DiscoveryInterval, "discovery-interval", o.DiscoveryInterval, "how often to scan the discovery workspaces for WorkspaceTypes")
+	flags.StringToStringVar(&o.DiscoveryLabels, "discovery-labels", o.DiscoveryLabels, "labels to put on discovered InitTargets, e.g. to make them match the --init-target-selector")
+	flags.StringSliceVar(&o.KindOrder, "kind-order", o.KindOrder, "comma-separated list of kinds (kind.group) defining the order in which objects are applied; use \"*\" to mark the position of all unlisted kinds")
+	flags.BoolVar(&o.ContinueOnError, "continue-on-error", o.ContinueOnError, "attempt to apply all objects even if some of them fail, instead of stopping at the first failing object")
+	flags.BoolVar(&o.EnableProvenance, "enable-provenance", o.EnableProvenance, "record the InitTarget, init source and InitTemplate as labels and annotations on every created object")
+	flags.StringVar(&o.ManagedBy, "managed-by", o.ManagedBy, "value of the app.kubernetes.io/managed-by label on created objects (empty to omit the label)")
+	flags.IntVar(&o.InitWorkers, "init-workers", o.InitWorkers, "default number of workspaces per InitTarget that are initialized in parallel")
+	flags.IntVar(&o.MaxConcurrentInitializations, "max-concurrent-initializations", o.MaxConcurrentInitializations, "maximum number of workspaces that are initialized in parallel across all InitTargets (0 for no limit)")
+	flags.Float32Var(&o.ClientQPS, "client-qps", o.ClientQPS, "maximum number of requests per second to workspaces, shared by all InitTargets (0 to disable rate limiting)")
+	flags.IntVar(&o.ClientBurst, "client-burst", o.ClientBurst, "maximum burst of requests to workspaces on top of --client-qps")
+	flags.DurationVar(&o.RetryInitialDelay, "retry-initial-delay", o.RetryInitialDelay, "default delay after the first failed initialization attempt, doubled for every further attempt")
+	flags.DurationVar(&o.RetryMaxDelay, "retry-max-delay", o.RetryMaxDelay, "default maximum delay between initialization attempts")
+	flags.DurationVar(&o.APIWaitTimeout, "api-wait-timeout", o.APIWaitTimeout, "how long to wait for missing APIs to become available in a new workspace before reporting an error (0 to wait forever)")
 	flags.BoolVar(&o.EnableLeaderElection, "enable-leader-election", o.EnableLeaderElection, "whether to perform leader election")
+	flags.StringVar(&o.LeaderElectionID, "leader-election-id", o.LeaderElectionID, "name of the Lease object used for leader election")
+	flags.StringVar(&o.LeaderElectionNamespace, "leader-election-namespace", o.LeaderElectionNamespace, "namespace in the config workspace where the leader election Lease is created")
+	flags.DurationVar(&o.LeaderElectionLeaseDuration, "leader-election-lease-duration", o.LeaderElectionLeaseDuration, "duration that non-leader candidates will wait to force acquire leadership")
+	flags.DurationVar(&o.LeaderElectionRenewDeadline, "leader-election-renew-deadline", o.LeaderElectionRenewDeadline, "duration that the acting leader will retry refreshing leadership before giving up")
+	flags.DurationVar(&o.LeaderElectionRetryPeriod, "leader-election-retry-period", o.LeaderElectionRetryPeriod, "duration the leader election clients should wait between tries of actions")
+	flags.StringVar(&o.TracingOptions.OTLPEndpoint, "tracing-otlp-endpoint", o.TracingOptions.OTLPEndpoint, "URL of an OTLP/HTTP collector to send traces to, e.g. http://localhost:4318 (tracing is disabled if empty)")
+	flags.Float64Var(&o.TracingOptions.SamplingRatio, "tracing-sampling-ratio", o.TracingOptions.SamplingRatio, "ratio of traces to sample, between 0 and 1")
+	flags.BoolVar(&o.EnableSharding, "enable-sharding", o.EnableSharding, "split workspaces between all replicas instead of using leader election")
+	flags.StringVar(&o.ShardGroup, "shard-group", o.ShardGroup, "name of the group of replicas that split the workspaces between them")
+	flags.StringVar(&o.ShardIdentity, "shard-identity", o.ShardIdentity, "unique name of this replica within the shard group (defaults to $POD_NAME or the hostname)")
+	flags.StringVar(&o.ShardNamespace, "shard-namespace", o.ShardNamespace, "namespace in the config workspace where the shard Leases are created")
+	flags.DurationVar(&o.ShardLeaseDuration, "shard-lease-duration", o.ShardLeaseDuration, "duration after which a replica that stopped renewing its Lease is removed from the shard group")
 	flags.StringVar(&o.MetricsAddr, "metrics-address", o.MetricsAddr, "host and port to serve Prometheus metrics via /metrics (HTTP)")
 	flags.StringVar(&o.HealthAddr, "health-address", o.HealthAddr, "host and port to serve probes via /readyz and /healthz (HTTP)")
 }
@@ -81,12 +233,88 @@ func (o *Options) Validate() error {
 		errs = append(errs, errors.New("--config-workspace is required"))
 	}
 
+	for i, ws := range o.AdditionalConfigWorkspaces {
+		if ws == "" || ws == o.ConfigWorkspace || slices.Contains(o.AdditionalConfigWorkspaces[:i], ws) {
+			errs = append(errs, fmt.Errorf("--additional-config-workspaces must contain unique workspaces other than --config-workspace, but %q is invalid", ws))
+		}
+	}
+
 	if s := o.InitTargetSelectorString; len(s) > 0 {
 		if _, err := labels.Parse(s); err != nil {
 			errs = append(errs, fmt.Errorf("invalid --init-target-selector %q: %w", s, err))
 		}
 	}
 
+	if o.EnableLeaderElection {
+		if o.LeaderElectionID == "" {
+			errs = append(errs, errors.New("--leader-election-id is required when leader election is enabled"))
+		}
+
+		if o.LeaderElectionNamespace == "" {
+			errs = append(errs, errors.New("--leader-election-namespace is required when leader election is enabled"))
+		}
+
+		if o.LeaderElectionRetryPeriod <= 0 || o.LeaderElectionRenewDeadline <= o.LeaderElectionRetryPeriod || o.LeaderElectionLeaseDuration <= o.LeaderElectionRenewDeadline {
+			errs = append(errs, errors.New("leader election timings must satisfy 0 < retry period < renew deadline < lease duration"))
+		}
+	}
+
+	if o.EnableSharding {
+		if o.EnableLeaderElection {
+			errs = append(errs, errors.New("--enable-sharding and --enable-leader-election are mutually exclusive"))
+		}
+
+		if o.ShardGroup == "" || o.ShardIdentity == "" || o.ShardNamespace == "" {
+			errs = append(errs, errors.New("--shard-group, --shard-identity and --shard-namespace are required when sharding is enabled"))
+		}
+
+		if o.ShardLeaseDuration < 3*time.Second {
+			errs = append(errs, errors.New("--shard-lease-duration must be at least 3s"))
+		}
+	}
+
+	if len(o.DiscoveryWorkspaces) > 0 && o.DiscoveryInterval <= 0 {
+		errs = append(errs, errors.New("--discovery-interval must be positive"))
+	}
+
+	if o.EnableProvenance && o.ManagedBy != "" {
+		if problems := validation.IsValidLabelValue(o.ManagedBy); len(problems) > 0 {
+			errs = append(errs, fmt.Errorf("invalid --managed-by %q: %s", o.ManagedBy, strings.Join(problems, ", ")))
+		}
+	}
+
+	if o.InitWorkers < 1 {
+		errs = append(errs, errors.New("--init-workers must be at least 1"))
+	}
+
+	if o.MaxConcurrentInitializations < 0 {
+		errs = append(errs, errors.New("--max-concurrent-initializations must not be negative"))
+	}
+
+	if o.ClientQPS < 0 {
+		errs = append(errs, errors.New("--client-qps must not be negative"))
+	}
+
+	if o.ClientQPS > 0 && o.ClientBurst < 1 {
+		errs = append(errs, errors.New("--client-burst must be at least 1"))
+	}
+
+	if o.RetryInitialDelay <= 0 || o.RetryMaxDelay < o.RetryInitialDelay {
+		errs = append(errs, errors.New("retry delays must satisfy 0 < --retry-initial-delay <= --retry-max-delay"))
+	}
+
+	if o.APIWaitTimeout < 0 {
+		errs = append(errs, errors.New("--api-wait-timeout must not be negative"))
+	}
+
+	if err := o.TracingOptions.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid --tracing-sampling-ratio: %w", err))
+	}
+
+	if _, err := manifest.NewSorter(o.KindOrder); err != nil {
+		errs = append(errs, fmt.Errorf("invalid --kind-order: %w", err))
+	}
+
 	return utilerrors.NewAggregate(errs)
 }
 
@@ -104,3 +332,51 @@ func (o *Options) Complete() error {
 
 	return utilerrors.NewAggregate(errs)
 }
+
+// Settings returns the options that can be changed at runtime.
+func (o *Options) Settings() settings.Settings {
+	return settings.Settings{
+		InitTargetSelector: o.InitTargetSelector,
+		InitWorkers:        o.InitWorkers,
+		RetryInitialDelay:  o.RetryInitialDelay,
+		RetryMaxDelay:      o.RetryMaxDelay,
+	}
+}
+
+// loadOptions applies the configuration file (if any) on top of the options
+// parsed from the command line and returns the validated, completed result.
+// The base options are not modified.
+func loadOptions(base Options, flags *pflag.FlagSet) (*Options, error) {
+	opts := base
+
+	if opts.ConfigFile != "" {
+		cfg, err := loadConfiguration(opts.ConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", opts.ConfigFile, err)
+		}
+
+		if err := cfg.apply(&opts, flags); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", opts.ConfigFile, err)
+		}
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := opts.Complete(); err != nil {
+		return nil, err
+	}
+
+	return &opts, nil
+}
+
+func defaultShardIdentity() string {
+	if name := os.Getenv("POD_NAME"); name != "" {
+		return name
+	}
+
+	hostname, _ := os.Hostname()
+
+	return hostname
+}
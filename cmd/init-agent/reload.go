@@ -0,0 +1,164 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+)
+
+// configReloader re-applies the configuration file whenever it changes or the
+// agent receives a SIGHUP. Only the options returned by Options.Settings, the
+// debug log level and the global concurrency limit are changed at runtime;
+// changes to all other options are only logged and require a restart.
+type configReloader struct {
+	log   *zap.SugaredLogger
+	base  Options
+	flags *pflag.FlagSet
+	apply func(opts *Options)
+
+	current *Options
+	content []byte
+}
+
+func newConfigReloader(log *zap.SugaredLogger, base Options, flags *pflag.FlagSet, current *Options, apply func(opts *Options)) *configReloader {
+	return &configReloader{
+		log:     log.Named("config").With("file", current.ConfigFile),
+		base:    base,
+		flags:   flags,
+		apply:   apply,
+		current: current,
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; every replica
+// needs to follow its configuration.
+func (r *configReloader) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *configReloader) Start(ctx context.Context) error {
+	filename := r.current.ConfigFile
+
+	// remember the file as it was loaded on startup
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	r.content = content
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch the directory instead of the file, so that files that are replaced
+	// instead of modified (e.g. mounted ConfigMaps) are followed as well.
+	if err := watcher.Add(filepath.Dir(filename)); err != nil {
+		return fmt.Errorf("failed to watch configuration file: %w", err)
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-watcher.Events:
+			r.reload(false)
+
+		case err := <-watcher.Errors:
+			r.log.Warnw("Error while watching configuration file", zap.Error(err))
+
+		case <-hangup:
+			r.log.Info("Received SIGHUP, reloading configuration")
+			r.reload(true)
+		}
+	}
+}
+
+// reload loads the configuration file and applies it. Unless forced, nothing
+// happens if the file has not changed.
+func (r *configReloader) reload(force bool) {
+	content, err := os.ReadFile(r.current.ConfigFile)
+	if err != nil {
+		r.log.Warnw("Failed to read configuration file", zap.Error(err))
+		return
+	}
+
+	if !force && bytes.Equal(content, r.content) {
+		return
+	}
+	r.content = content
+
+	opts, err := loadOptions(r.base, r.flags)
+	if err != nil {
+		r.log.Errorw("Invalid configuration, keeping the current one", zap.Error(err))
+		return
+	}
+
+	if !reflect.DeepEqual(staticOptions(opts), staticOptions(r.current)) {
+		r.log.Warn("Configuration contains changes that require a restart of the agent, only applying reloadable options")
+	}
+
+	// keep everything that cannot be reloaded as it is
+	updated := *r.current
+	updated.setReloadable(opts)
+
+	r.apply(&updated)
+	r.current = &updated
+
+	r.log.Infow("Configuration has been reloaded",
+		"debug", updated.LogOptions.Debug,
+		"targetselector", updated.InitTargetSelector.String(),
+		"initworkers", updated.InitWorkers,
+		"maxconcurrentinitializations", updated.MaxConcurrentInitializations,
+	)
+}
+
+// setReloadable copies all options that can be changed at runtime.
+func (o *Options) setReloadable(from *Options) {
+	o.LogOptions.Debug = from.LogOptions.Debug
+	o.InitTargetSelectorString = from.InitTargetSelectorString
+	o.InitTargetSelector = from.InitTargetSelector
+	o.InitWorkers = from.InitWorkers
+	o.MaxConcurrentInitializations = from.MaxConcurrentInitializations
+	o.RetryInitialDelay = from.RetryInitialDelay
+	o.RetryMaxDelay = from.RetryMaxDelay
+}
+
+// staticOptions returns the options without all fields that are reloadable.
+func staticOptions(opts *Options) Options {
+	static := *opts
+	static.setReloadable(&Options{})
+
+	return static
+}
@@ -21,6 +21,7 @@ source hack/lib.sh
 
 CRD_DIR=deploy/crd
 KCP_CRD_DIR="$CRD_DIR/kcp.io"
+APIEXPORT_DIR=deploy/apiexport
 rm -rf -- "$KCP_CRD_DIR"
 mkdir -p "$KCP_CRD_DIR"
 
@@ -47,3 +48,12 @@ beautify() {
 for f in $KCP_CRD_DIR/*.yaml; do
   beautify "$f"
 done
+
+echodate "Generating APIResourceSchemas and APIExport…"
+
+APIGEN="$(UGET_PRINT_PATH=relative make --no-print-directory install-apigen)"
+
+# the APIResourceSchemas are only replaced if the CRDs have changed
+"$APIGEN" \
+  --input-dir "$KCP_CRD_DIR" \
+  --output-dir "$APIEXPORT_DIR"
@@ -0,0 +1,295 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discoverycontroller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kcp-dev/init-agent/internal/kcp"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+	kcptenancyv1alpha1 "github.com/kcp-dev/sdk/apis/tenancy/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	ControllerName = "initagent-discovery-controller"
+)
+
+type Options struct {
+	// Workspaces are the workspaces (paths or cluster names) in which
+	// WorkspaceTypes are discovered.
+	Workspaces []string
+
+	// Interval is how often the workspaces are scanned for WorkspaceTypes.
+	Interval time.Duration
+
+	// Labels are placed on all managed InitTargets, e.g. to make them match
+	// the agent's InitTarget selector.
+	Labels map[string]string
+
+	// ShardFilter, if set, restricts the controller to the InitTargets that
+	// are assigned to this replica of the init-agent. This is required when
+	// sharding is enabled, since there is no leader election then and every
+	// replica runs this controller.
+	ShardFilter ShardFilter
+}
+
+// ShardFilter decides which InitTargets are managed by this replica of the
+// init-agent.
+type ShardFilter interface {
+	// Owns returns true if this replica is responsible for the given key.
+	Owns(key string) bool
+}
+
+// Reconciler manages one InitTarget in the config workspace for each
+// discovered WorkspaceType that has the TemplatesAnnotation.
+type Reconciler struct {
+	localClient   ctrlruntimeclient.Client
+	clusterClient kcp.ClusterClient
+	log           *zap.SugaredLogger
+	opts          Options
+}
+
+// Add creates a new controller and adds it to the given manager. The controller
+// only runs while the manager is the leader (if leader election is enabled);
+// with sharding, it runs on every replica and uses the ShardFilter instead.
+func Add(mgr manager.Manager, log *zap.SugaredLogger, clusterClient kcp.ClusterClient, opts Options) error {
+	reconciler := &Reconciler{
+		localClient:   mgr.GetClient(),
+		clusterClient: clusterClient,
+		log:           log.Named(ControllerName),
+		opts:          opts,
+	}
+
+	if err := mgr.Add(reconciler); err != nil {
+		return fmt.Errorf("failed to add discovery runnable: %w", err)
+	}
+
+	return nil
+}
+
+// Start periodically discovers WorkspaceTypes until the context is cancelled.
+// WorkspaceTypes live in arbitrary workspaces that the agent's manager does
+// not watch, so they are polled instead.
+func (r *Reconciler) Start(ctx context.Context) error {
+	for {
+		if err := r.sync(ctx); err != nil {
+			r.log.Warnw("Failed to sync discovered InitTargets", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.opts.Interval):
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (r *Reconciler) NeedLeaderElection() bool {
+	return true
+}
+
+func (r *Reconciler) sync(ctx context.Context) error {
+	desired := map[string]*initializationv1alpha1.InitTarget{}
+
+	// workspaces that could not be scanned; their InitTargets are left alone
+	// to not delete them just because of a temporary error
+	unknown := sets.New[string]()
+
+	for _, workspace := range r.opts.Workspaces {
+		wsts, err := r.listWorkspaceTypes(ctx, workspace)
+		if err != nil {
+			r.log.Warnw("Failed to discover WorkspaceTypes", "workspace", workspace, zap.Error(err))
+			unknown.Insert(workspace)
+			continue
+		}
+
+		for i := range wsts {
+			if target := desiredInitTarget(workspace, &wsts[i], r.opts.Labels); target != nil {
+				desired[target.Name] = target
+			}
+		}
+	}
+
+	existing := &initializationv1alpha1.InitTargetList{}
+	if err := r.localClient.List(ctx, existing, ctrlruntimeclient.MatchingLabels{initializationv1alpha1.DiscoveredLabel: "true"}); err != nil {
+		return fmt.Errorf("failed to list InitTargets: %w", err)
+	}
+
+	var errs []error
+
+	for i := range existing.Items {
+		target := &existing.Items[i]
+
+		// another replica is responsible for this InitTarget
+		if !r.owns(target.Name) {
+			continue
+		}
+
+		want, ok := desired[target.Name]
+		if !ok {
+			if unknown.Has(target.Annotations[initializationv1alpha1.DiscoveredFromAnnotation]) {
+				continue
+			}
+
+			r.log.Infow("Deleting InitTarget for WorkspaceType that is gone or not annotated anymore", "name", target.Name)
+			if err := r.localClient.Delete(ctx, target); ctrlruntimeclient.IgnoreNotFound(err) != nil {
+				errs = append(errs, fmt.Errorf("failed to delete InitTarget %s: %w", target.Name, err))
+			}
+
+			continue
+		}
+
+		delete(desired, target.Name)
+
+		if err := r.updateInitTarget(ctx, target, want); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, target := range desired {
+		if !r.owns(target.Name) {
+			continue
+		}
+
+		r.log.Infow("Creating InitTarget for discovered WorkspaceType", "name", target.Name, "workspacetype", target.Spec.WorkspaceTypeReference.Name)
+		if err := r.localClient.Create(ctx, target); err != nil && !apierrors.IsAlreadyExists(err) {
+			errs = append(errs, fmt.Errorf("failed to create InitTarget %s: %w", target.Name, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// owns returns whether this replica manages the InitTarget with the given
+// name. During a rebalancing, two replicas can briefly both consider
+// themselves responsible, which is harmless since all changes are idempotent.
+func (r *Reconciler) owns(name string) bool {
+	return r.opts.ShardFilter == nil || r.opts.ShardFilter.Owns(name)
+}
+
+func (r *Reconciler) updateInitTarget(ctx context.Context, target, want *initializationv1alpha1.InitTarget) error {
+	oldTarget := target.DeepCopy()
+
+	target.Spec.WorkspaceTypeReference = want.Spec.WorkspaceTypeReference
+	target.Spec.Sources = want.Spec.Sources
+
+	if target.Labels == nil {
+		target.Labels = map[string]string{}
+	}
+	maps.Copy(target.Labels, want.Labels)
+
+	if equality.Semantic.DeepEqual(oldTarget, target) {
+		return nil
+	}
+
+	r.log.Infow("Updating InitTarget for discovered WorkspaceType", "name", target.Name)
+
+	if err := r.localClient.Patch(ctx, target, ctrlruntimeclient.MergeFrom(oldTarget)); err != nil {
+		return fmt.Errorf("failed to update InitTarget %s: %w", target.Name, err)
+	}
+
+	return nil
+}
+
+func (r *Reconciler) listWorkspaceTypes(ctx context.Context, workspace string) ([]kcptenancyv1alpha1.WorkspaceType, error) {
+	scheme := runtime.NewScheme()
+
+	if err := kcptenancyv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to register local scheme %s: %w", kcptenancyv1alpha1.SchemeGroupVersion, err)
+	}
+
+	wstClient, err := r.clusterClient.Cluster(logicalcluster.Name(workspace), scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	wsts := &kcptenancyv1alpha1.WorkspaceTypeList{}
+	if err := wstClient.List(ctx, wsts); err != nil {
+		return nil, err
+	}
+
+	return wsts.Items, nil
+}
+
+// desiredInitTarget returns the InitTarget for the given WorkspaceType, or nil
+// if the WorkspaceType does not list any templates.
+func desiredInitTarget(workspace string, wst *kcptenancyv1alpha1.WorkspaceType, extraLabels map[string]string) *initializationv1alpha1.InitTarget {
+	var sources []initializationv1alpha1.InitSource
+	for name := range strings.SplitSeq(wst.Annotations[initializationv1alpha1.TemplatesAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			sources = append(sources, initializationv1alpha1.InitSource{
+				Template: &initializationv1alpha1.TemplateInitSource{Name: name},
+			})
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil
+	}
+
+	labels := maps.Clone(extraLabels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[initializationv1alpha1.DiscoveredLabel] = "true"
+
+	return &initializationv1alpha1.InitTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   initTargetName(workspace, wst.Name),
+			Labels: labels,
+			Annotations: map[string]string{
+				initializationv1alpha1.DiscoveredFromAnnotation: workspace,
+			},
+		},
+		Spec: initializationv1alpha1.InitTargetSpec{
+			WorkspaceTypeReference: initializationv1alpha1.WorkspaceTypeReference{
+				Path: workspace,
+				Name: wst.Name,
+			},
+			Sources: sources,
+		},
+	}
+}
+
+// initTargetName returns a stable name for the InitTarget of the given
+// WorkspaceType. The hash makes sure that equally named WorkspaceTypes in
+// different workspaces do not collide.
+func initTargetName(workspace string, wstName string) string {
+	hash := sha256.Sum256([]byte(workspace + ":" + wstName))
+
+	return fmt.Sprintf("discovered-%s-%s", wstName, hex.EncodeToString(hash[:])[:8])
+}
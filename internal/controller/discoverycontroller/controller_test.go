@@ -0,0 +1,214 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discoverycontroller
+
+import (
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/kcp-dev/init-agent/internal/sharding"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+	kcptenancyv1alpha1 "github.com/kcp-dev/sdk/apis/tenancy/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDesiredInitTarget(t *testing.T) {
+	newWST := func(templates string) *kcptenancyv1alpha1.WorkspaceType {
+		wst := &kcptenancyv1alpha1.WorkspaceType{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+		}
+
+		if templates != "" {
+			wst.Annotations = map[string]string{initializationv1alpha1.TemplatesAnnotation: templates}
+		}
+
+		return wst
+	}
+
+	if target := desiredInitTarget("root:types", newWST(""), nil); target != nil {
+		t.Fatalf("Expected no InitTarget for unannotated WorkspaceType, got %v.", target)
+	}
+
+	if target := desiredInitTarget("root:types", newWST(" , "), nil); target != nil {
+		t.Fatalf("Expected no InitTarget for WorkspaceType without templates, got %v.", target)
+	}
+
+	target := desiredInitTarget("root:types", newWST("a, b,,c"), map[string]string{"team": "platform"})
+	if target == nil {
+		t.Fatal("Expected an InitTarget.")
+	}
+
+	var templates []string
+	for _, src := range target.Spec.Sources {
+		templates = append(templates, src.Template.Name)
+	}
+
+	if len(templates) != 3 || templates[0] != "a" || templates[1] != "b" || templates[2] != "c" {
+		t.Errorf("Expected templates [a b c], got %v.", templates)
+	}
+
+	if ref := target.Spec.WorkspaceTypeReference; ref.Path != "root:types" || ref.Name != "dev" {
+		t.Errorf("Unexpected WorkspaceType reference %v.", ref)
+	}
+
+	if target.Labels[initializationv1alpha1.DiscoveredLabel] != "true" || target.Labels["team"] != "platform" {
+		t.Errorf("Unexpected labels %v.", target.Labels)
+	}
+
+	if target.Annotations[initializationv1alpha1.DiscoveredFromAnnotation] != "root:types" {
+		t.Errorf("Unexpected annotations %v.", target.Annotations)
+	}
+}
+
+func TestInitTargetName(t *testing.T) {
+	a := initTargetName("root:a", "dev")
+	b := initTargetName("root:b", "dev")
+
+	if a == b {
+		t.Fatalf("Expected different names for WorkspaceTypes in different workspaces, got %q.", a)
+	}
+
+	if a != initTargetName("root:a", "dev") {
+		t.Fatal("Expected names to be stable.")
+	}
+}
+
+// fakeClusterClient serves the same WorkspaceTypes in every workspace.
+type fakeClusterClient struct {
+	client ctrlruntimeclient.Client
+}
+
+func (c *fakeClusterClient) Cluster(logicalcluster.Name, *runtime.Scheme) (ctrlruntimeclient.Client, error) {
+	return c.client, nil
+}
+
+func (c *fakeClusterClient) ClusterConfig(logicalcluster.Name) *rest.Config {
+	return &rest.Config{}
+}
+
+// staticShard is a replica of a shard group with fixed members.
+type staticShard struct {
+	members  []string
+	identity string
+}
+
+func (s staticShard) Owns(key string) bool {
+	return sharding.Owner(s.members, key) == s.identity
+}
+
+func TestShardedReplicasManageDisjointInitTargets(t *testing.T) {
+	wstScheme := runtime.NewScheme()
+	if err := kcptenancyv1alpha1.AddToScheme(wstScheme); err != nil {
+		t.Fatalf("Failed to build scheme: %v", err)
+	}
+
+	targetScheme := runtime.NewScheme()
+	if err := initializationv1alpha1.AddToScheme(targetScheme); err != nil {
+		t.Fatalf("Failed to build scheme: %v", err)
+	}
+
+	var (
+		wsts     []ctrlruntimeclient.Object
+		expected []string
+	)
+	for _, name := range []string{"dev", "prod", "staging", "test", "qa", "demo"} {
+		wsts = append(wsts, &kcptenancyv1alpha1.WorkspaceType{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{initializationv1alpha1.TemplatesAnnotation: "base"},
+			},
+		})
+		expected = append(expected, initTargetName("root:types", name))
+	}
+
+	stale := desiredInitTarget("root:types", &kcptenancyv1alpha1.WorkspaceType{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "gone",
+			Annotations: map[string]string{initializationv1alpha1.TemplatesAnnotation: "base"},
+		},
+	}, nil)
+
+	clusterClient := &fakeClusterClient{client: fake.NewClientBuilder().WithScheme(wstScheme).WithObjects(wsts...).Build()}
+	localClient := fake.NewClientBuilder().WithScheme(targetScheme).WithObjects(stale).Build()
+
+	members := []string{"replica-a", "replica-b"}
+	newReplica := func(identity string) *Reconciler {
+		return &Reconciler{
+			localClient:   localClient,
+			clusterClient: clusterClient,
+			log:           zap.NewNop().Sugar(),
+			opts: Options{
+				Workspaces:  []string{"root:types"},
+				ShardFilter: staticShard{members: members, identity: identity},
+			},
+		}
+	}
+
+	listTargets := func() []string {
+		targets := &initializationv1alpha1.InitTargetList{}
+		if err := localClient.List(t.Context(), targets); err != nil {
+			t.Fatalf("Failed to list InitTargets: %v", err)
+		}
+
+		var names []string
+		for _, target := range targets.Items {
+			names = append(names, target.Name)
+		}
+
+		slices.Sort(names)
+		return names
+	}
+
+	first := newReplica(members[0])
+	if err := first.sync(t.Context()); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+
+	// the first replica must only have touched its own InitTargets
+	for _, name := range listTargets() {
+		owner := sharding.Owner(members, name)
+		if name == stale.Name {
+			if owner == members[0] {
+				t.Errorf("Expected the stale InitTarget %q to be deleted by its owner.", name)
+			}
+			continue
+		}
+
+		if owner != members[0] {
+			t.Errorf("Expected InitTarget %q to be left to %s, but it was created by %s.", name, owner, members[0])
+		}
+	}
+
+	second := newReplica(members[1])
+	if err := second.sync(t.Context()); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+
+	slices.Sort(expected)
+	if names := listTargets(); !slices.Equal(names, expected) {
+		t.Fatalf("Expected both replicas together to manage %v, got %v.", expected, names)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
+)
+
+const (
+	apiPollInitialInterval = 1 * time.Second
+	apiPollMaxInterval     = 30 * time.Second
+)
+
+// apiWaiter keeps track of clusters that are waiting for APIs to become
+// available. Instead of blindly requeuing these clusters, it polls each
+// cluster's RESTMapper in the background (with an increasing interval) and
+// requeues the cluster once all missing APIs have appeared. Since the
+// RESTMapper used by ctrl-runtime reloads a group whenever a kind cannot be
+// found in it, polling it also refreshes exactly the groups that are missing.
+type apiWaiter struct {
+	timeout time.Duration
+
+	lock    sync.Mutex
+	ctx     context.Context
+	queue   workqueue.TypedRateLimitingInterface[mcreconcile.Request]
+	waiting map[string]*apiWait
+}
+
+type apiWait struct {
+	since  map[schema.GroupVersionKind]time.Time
+	cancel context.CancelFunc
+}
+
+func newAPIWaiter(timeout time.Duration) *apiWaiter {
+	return &apiWaiter{
+		timeout: timeout,
+		waiting: map[string]*apiWait{},
+	}
+}
+
+// Source returns a source for the init controller that gives the waiter access
+// to the controller's workqueue.
+func (w *apiWaiter) Source() source.TypedSource[mcreconcile.Request] {
+	return source.TypedFunc[mcreconcile.Request](func(ctx context.Context, queue workqueue.TypedRateLimitingInterface[mcreconcile.Request]) error {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+
+		w.ctx = ctx
+		w.queue = queue
+
+		return nil
+	})
+}
+
+// Wait registers the cluster as waiting for the given kinds and starts polling
+// for them. It returns all kinds that have been missing for longer than the
+// configured timeout.
+func (w *apiWaiter) Wait(clusterName string, mapper meta.RESTMapper, gvks []schema.GroupVersionKind) []schema.GroupVersionKind {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	wait, exists := w.waiting[clusterName]
+	if !exists {
+		wait = &apiWait{since: map[schema.GroupVersionKind]time.Time{}}
+		w.waiting[clusterName] = wait
+	}
+
+	// forget about kinds that have appeared in the meantime
+	for gvk := range wait.since {
+		if !slices.Contains(gvks, gvk) {
+			delete(wait.since, gvk)
+		}
+	}
+
+	now := time.Now()
+
+	var timedOut []schema.GroupVersionKind
+	for _, gvk := range gvks {
+		since, ok := wait.since[gvk]
+		if !ok {
+			since = now
+			wait.since[gvk] = since
+		}
+
+		if w.timeout > 0 && now.Sub(since) >= w.timeout {
+			timedOut = append(timedOut, gvk)
+		}
+	}
+
+	if wait.cancel != nil {
+		wait.cancel()
+		wait.cancel = nil
+	}
+
+	// only keep polling for APIs that have not timed out yet
+	if len(timedOut) == 0 && w.ctx != nil {
+		ctx, cancel := context.WithCancel(w.ctx)
+		wait.cancel = cancel
+
+		go w.poll(ctx, clusterName, mapper, slices.Clone(gvks))
+	}
+
+	return timedOut
+}
+
+// Forget stops waiting for APIs in the given cluster.
+func (w *apiWaiter) Forget(clusterName string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if wait, exists := w.waiting[clusterName]; exists {
+		if wait.cancel != nil {
+			wait.cancel()
+		}
+		delete(w.waiting, clusterName)
+	}
+}
+
+func (w *apiWaiter) poll(ctx context.Context, clusterName string, mapper meta.RESTMapper, gvks []schema.GroupVersionKind) {
+	start := time.Now()
+	interval := apiPollInitialInterval
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		// Requeue once the APIs are available or once the timeout has been
+		// reached, so that the reconciler can report the failure.
+		if apisAvailable(mapper, gvks) || (w.timeout > 0 && time.Since(start) >= w.timeout) {
+			w.queue.Add(mcreconcile.Request{
+				ClusterName: clusterName,
+				Request: reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "cluster"},
+				},
+			})
+			return
+		}
+
+		interval = min(2*interval, apiPollMaxInterval)
+	}
+}
+
+func apisAvailable(mapper meta.RESTMapper, gvks []schema.GroupVersionKind) bool {
+	for _, gvk := range gvks {
+		if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
+)
+
+func TestAPIWaiterRequeuesWhenAPIAppears(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[mcreconcile.Request]())
+	defer queue.ShutDown()
+
+	waiter := newAPIWaiter(0)
+	if err := waiter.Source().Start(t.Context(), queue); err != nil {
+		t.Fatalf("Failed to start source: %v", err)
+	}
+
+	if timedOut := waiter.Wait("my-cluster", mapper, []schema.GroupVersionKind{gvk}); len(timedOut) > 0 {
+		t.Fatalf("Expected no timeouts, got %v.", timedOut)
+	}
+
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+
+	deadline := time.After(5 * time.Second)
+	for queue.Len() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("Cluster was not requeued after the API appeared.")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	req, _ := queue.Get()
+	if req.ClusterName != "my-cluster" {
+		t.Fatalf("Expected my-cluster to be requeued, got %q.", req.ClusterName)
+	}
+}
+
+func TestAPIWaiterTimeout(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+
+	waiter := newAPIWaiter(time.Minute)
+
+	if timedOut := waiter.Wait("my-cluster", mapper, []schema.GroupVersionKind{gvk}); len(timedOut) > 0 {
+		t.Fatalf("Expected no timeouts, got %v.", timedOut)
+	}
+
+	// pretend we have been waiting for a long time already
+	waiter.waiting["my-cluster"].since[gvk] = time.Now().Add(-time.Hour)
+
+	if timedOut := waiter.Wait("my-cluster", mapper, []schema.GroupVersionKind{gvk}); len(timedOut) != 1 {
+		t.Fatalf("Expected API to have timed out, got %v.", timedOut)
+	}
+
+	waiter.Forget("my-cluster")
+
+	if timedOut := waiter.Wait("my-cluster", mapper, []schema.GroupVersionKind{gvk}); len(timedOut) > 0 {
+		t.Fatalf("Expected timeout to be reset after forgetting the cluster, got %v.", timedOut)
+	}
+}
@@ -18,17 +18,25 @@ package initcontroller
 
 import (
 	"context"
+	"slices"
+	"time"
 
 	"github.com/go-logr/zapr"
 	"go.uber.org/zap"
 
 	"github.com/kcp-dev/init-agent/internal/initialize/source"
 	"github.com/kcp-dev/init-agent/internal/manifest"
+	"github.com/kcp-dev/init-agent/internal/metrics"
+	"github.com/kcp-dev/init-agent/internal/settings"
 	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
 
+	"github.com/kcp-dev/logicalcluster/v3"
 	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
 
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	mcbuilder "sigs.k8s.io/multicluster-runtime/pkg/builder"
 	mccontroller "sigs.k8s.io/multicluster-runtime/pkg/controller"
 	mcmanager "sigs.k8s.io/multicluster-runtime/pkg/manager"
@@ -36,17 +44,74 @@ import (
 
 const (
 	ControllerName = "initagent-init"
+
+	// suspendedRequeueInterval is how often workspaces of a suspended
+	// InitTarget are checked for whether the InitTarget has been resumed.
+	suspendedRequeueInterval = 15 * time.Second
 )
 
 type InitTargetProvider func(ctx context.Context) (*initializationv1alpha1.InitTarget, error)
 
+// ResultReporter is called with the outcome of every initialization attempt
+// that either completed (err is nil) or failed, so that it can be reflected in
+// the InitTarget's status.
+type ResultReporter func(workspace logicalcluster.Path, err error)
+
+// Options configure the behaviour of the init controller.
+type Options struct {
+	// InitTarget identifies the InitTarget in metrics. Since InitTarget names
+	// are only unique within their workspace, this should include the
+	// workspace, e.g. "root:config/my-target".
+	InitTarget string
+
+	// NumWorkers is the number of clusters that are initialized in parallel.
+	NumWorkers int
+
+	// ContinueOnError makes the controller attempt to apply all objects of all
+	// init sources, even if some of them fail, instead of stopping at the first
+	// failing object.
+	ContinueOnError bool
+
+	// APIWaitTimeout is the duration after which a missing API is treated as
+	// an error instead of something to wait for. Zero means waiting forever.
+	APIWaitTimeout time.Duration
+
+	// ShardFilter, if set, restricts the controller to the clusters that are
+	// assigned to this replica of the init-agent.
+	ShardFilter ShardFilter
+
+	// Limiter, if set, is shared by all init controllers to limit the total
+	// number of concurrent initializations.
+	Limiter *Limiter
+
+	// Provenance makes the controller record the InitTarget, init source and
+	// InitTemplate on every object it creates.
+	Provenance bool
+
+	// ManagedBy is the value of the app.kubernetes.io/managed-by label that is
+	// placed on created objects if Provenance is enabled. The label is omitted
+	// if this is empty.
+	ManagedBy string
+
+	// Settings, if set, provide the agent's current default retry delays.
+	Settings *settings.Store
+}
+
 type Reconciler struct {
 	remoteManager   mcmanager.Manager
 	targetProvider  InitTargetProvider
+	reporter        ResultReporter
 	log             *zap.SugaredLogger
 	sourceFactory   *source.Factory
 	manifestApplier manifest.Applier
 	initializer     kcpcorev1alpha1.LogicalClusterInitializer
+	opts            Options
+	apiWaiter       *apiWaiter
+	pending         *pendingTracker
+	exhausted       *clusterSet
+	suspended       *clusterSet
+	priorities      *priorityTracker
+	shards          *shardTracker
 }
 
 // Create creates a new controller and importantly does *not* add it to the manager,
@@ -54,27 +119,84 @@ type Reconciler struct {
 func Create(
 	remoteManager mcmanager.Manager,
 	targetProvider InitTargetProvider,
+	reporter ResultReporter,
 	sourceFactory *source.Factory,
 	manifestApplier manifest.Applier,
 	initializer kcpcorev1alpha1.LogicalClusterInitializer,
 	log *zap.SugaredLogger,
-	numWorkers int,
+	opts Options,
 ) error {
+	waiter := newAPIWaiter(opts.APIWaitTimeout)
+	shards := newShardTracker(opts.ShardFilter)
+
+	// Once this controller is stopped, its initializer is not handled by
+	// this agent anymore and should not be reported.
+	err := remoteManager.GetLocalManager().Add(manager.RunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		metrics.PendingWorkspaces.DeleteLabelValues(string(initializer))
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	reconciler := &Reconciler{
+		remoteManager:   remoteManager,
+		targetProvider:  targetProvider,
+		reporter:        reporter,
+		log:             log.Named(ControllerName),
+		sourceFactory:   sourceFactory,
+		manifestApplier: manifestApplier,
+		initializer:     initializer,
+		opts:            opts,
+		apiWaiter:       waiter,
+		pending:         newPendingTracker(metrics.PendingWorkspaces.WithLabelValues(string(initializer))),
+		shards:          shards,
+		exhausted:       newClusterSet(),
+		suspended:       newClusterSet(),
+		priorities:      newPriorityTracker(),
+	}
+
 	return mcbuilder.
 		ControllerManagedBy(remoteManager).
 		Named(ControllerName).
 		WithOptions(mccontroller.Options{
-			MaxConcurrentReconciles: numWorkers,
+			MaxConcurrentReconciles: opts.NumWorkers,
 			SkipNameValidation:      ptr.To(true),
 			Logger:                  zapr.NewLogger(log.Desugar()),
+			// During a backlog, initialize important workspaces first.
+			NewQueue: newPriorityQueue(reconciler.priorities.Priority),
 		}).
-		For(&kcpcorev1alpha1.LogicalCluster{}).
-		Complete(&Reconciler{
-			remoteManager:   remoteManager,
-			targetProvider:  targetProvider,
-			log:             log.Named(ControllerName),
-			sourceFactory:   sourceFactory,
-			manifestApplier: manifestApplier,
-			initializer:     initializer,
-		})
+		For(&kcpcorev1alpha1.LogicalCluster{}, mcbuilder.WithPredicates(reconciler.priorities.Predicate(), initializationChanged())).
+		WatchesRawSource(waiter.Source()).
+		WatchesRawSource(shards.Source()).
+		Complete(reconciler)
+}
+
+// initializationChanged filters out updates to LogicalClusters that are
+// irrelevant for their initialization. Most importantly, this ignores the
+// annotation this controller places itself to track failed attempts, which
+// would otherwise immediately requeue the cluster and bypass the retry delay.
+func initializationChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCluster, ok := e.ObjectOld.(*kcpcorev1alpha1.LogicalCluster)
+			if !ok {
+				return true
+			}
+
+			newCluster, ok := e.ObjectNew.(*kcpcorev1alpha1.LogicalCluster)
+			if !ok {
+				return true
+			}
+
+			// Removing an initializer from the failed annotation retries it.
+			failedAnnotation := initializationv1alpha1.FailedAnnotation
+
+			return oldCluster.Generation != newCluster.Generation ||
+				!oldCluster.DeletionTimestamp.Equal(newCluster.DeletionTimestamp) ||
+				!slices.Equal(oldCluster.Status.Initializers, newCluster.Status.Initializers) ||
+				oldCluster.Annotations[failedAnnotation] != newCluster.Annotations[failedAnnotation]
+		},
+	}
 }
@@ -0,0 +1,176 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter limits the number of workspaces that are initialized concurrently
+// across all init controllers sharing it. When no slot is free, waiting
+// initializations are admitted by priority (see priorityDeadline). A nil
+// Limiter does not limit anything.
+type Limiter struct {
+	lock    sync.Mutex
+	limit   int
+	inUse   int
+	waiters waiterHeap
+	now     func() time.Time
+}
+
+type waiter struct {
+	deadline time.Time
+	since    time.Time
+	ready    chan struct{}
+	pos      int
+}
+
+// NewLimiter returns a limiter allowing up to limit concurrent initializations.
+// A limit of zero or less means no limit.
+func NewLimiter(limit int) *Limiter {
+	return &Limiter{
+		limit: limit,
+		now:   time.Now,
+	}
+}
+
+// SetLimit changes the number of allowed concurrent initializations. If the
+// limit is lowered, running initializations are not interrupted, but no new
+// ones are admitted until enough of them have finished.
+func (l *Limiter) SetLimit(limit int) {
+	if l == nil {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.limit = limit
+	l.admitLocked()
+}
+
+// Acquire blocks until a slot is available or the context is cancelled.
+// Higher priorities are served first.
+func (l *Limiter) Acquire(ctx context.Context, priority int) error {
+	if l == nil {
+		return nil
+	}
+
+	l.lock.Lock()
+	if l.hasCapacityLocked() && l.waiters.Len() == 0 {
+		l.inUse++
+		l.lock.Unlock()
+		return nil
+	}
+
+	now := l.now()
+	w := &waiter{
+		deadline: priorityDeadline(now, priority),
+		since:    now,
+		ready:    make(chan struct{}),
+	}
+	heap.Push(&l.waiters, w)
+	l.lock.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		select {
+		case <-w.ready:
+			// the slot was handed over in the meantime, pass it on
+			l.releaseLocked()
+		default:
+			heap.Remove(&l.waiters, w.pos)
+		}
+
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot that was previously acquired.
+func (l *Limiter) Release() {
+	if l == nil {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.releaseLocked()
+}
+
+func (l *Limiter) releaseLocked() {
+	l.inUse--
+	l.admitLocked()
+}
+
+// admitLocked hands out free slots to the waiters with the highest priority.
+func (l *Limiter) admitLocked() {
+	for l.hasCapacityLocked() && l.waiters.Len() > 0 {
+		w := heap.Pop(&l.waiters).(*waiter)
+		l.inUse++
+		close(w.ready)
+	}
+}
+
+func (l *Limiter) hasCapacityLocked() bool {
+	return l.limit <= 0 || l.inUse < l.limit
+}
+
+// waiterHeap implements heap.Interface, with the earliest deadline first.
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int {
+	return len(h)
+}
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].deadline.Equal(h[j].deadline) {
+		return h[i].since.Before(h[j].since)
+	}
+
+	return h[i].deadline.Before(h[j].deadline)
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos = i
+	h[j].pos = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.pos = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+
+	return w
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(2)
+
+	for range 2 {
+		if err := limiter.Acquire(context.Background(), 0); err != nil {
+			t.Fatalf("Failed to acquire slot: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Acquire(ctx, 0); err == nil {
+		t.Fatal("Expected acquiring a third slot to block until the context is cancelled.")
+	}
+
+	limiter.Release()
+
+	if err := limiter.Acquire(context.Background(), 0); err != nil {
+		t.Fatalf("Failed to acquire released slot: %v", err)
+	}
+}
+
+func TestLimiterPriority(t *testing.T) {
+	limiter := NewLimiter(1)
+
+	if err := limiter.Acquire(context.Background(), 0); err != nil {
+		t.Fatalf("Failed to acquire slot: %v", err)
+	}
+
+	admitted := make(chan string, 2)
+	wait := func(name string, priority int) {
+		if err := limiter.Acquire(context.Background(), priority); err != nil {
+			t.Errorf("Failed to acquire slot: %v", err)
+		}
+		admitted <- name
+	}
+
+	go wait("low", 0)
+	waitForWaiters(t, limiter, 1)
+	go wait("high", 5)
+	waitForWaiters(t, limiter, 2)
+
+	limiter.Release()
+	if first := <-admitted; first != "high" {
+		t.Fatalf("Expected the higher priority to be admitted first, but got %q.", first)
+	}
+
+	limiter.Release()
+	if second := <-admitted; second != "low" {
+		t.Fatalf("Expected the lower priority to be admitted second, but got %q.", second)
+	}
+}
+
+func waitForWaiters(t *testing.T, limiter *Limiter, n int) {
+	t.Helper()
+
+	for range 100 {
+		limiter.lock.Lock()
+		waiting := limiter.waiters.Len()
+		limiter.lock.Unlock()
+
+		if waiting == n {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("Expected %d waiters.", n)
+}
+
+func TestLimiterSetLimit(t *testing.T) {
+	limiter := NewLimiter(1)
+
+	if err := limiter.Acquire(context.Background(), 0); err != nil {
+		t.Fatalf("Failed to acquire slot: %v", err)
+	}
+
+	admitted := make(chan struct{})
+	go func() {
+		if err := limiter.Acquire(context.Background(), 0); err != nil {
+			t.Errorf("Failed to acquire slot: %v", err)
+		}
+		close(admitted)
+	}()
+
+	waitForWaiters(t, limiter, 1)
+
+	// raising the limit admits waiting initializations right away
+	limiter.SetLimit(2)
+	<-admitted
+}
+
+func TestUnlimitedLimiter(t *testing.T) {
+	for _, limiter := range []*Limiter{nil, NewLimiter(0)} {
+		for range 10 {
+			if err := limiter.Acquire(context.Background(), 0); err != nil {
+				t.Fatalf("Expected unlimited limiter to never block, but got %v", err)
+			}
+		}
+
+		limiter.Release()
+	}
+}
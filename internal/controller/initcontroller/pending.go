@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// pendingTracker keeps track of the clusters that are currently waiting for
+// this controller's initializer to be removed and reflects their number in
+// a gauge.
+type pendingTracker struct {
+	gauge    prometheus.Gauge
+	lock     sync.Mutex
+	clusters map[string]struct{}
+}
+
+func newPendingTracker(gauge prometheus.Gauge) *pendingTracker {
+	return &pendingTracker{
+		gauge:    gauge,
+		clusters: map[string]struct{}{},
+	}
+}
+
+func (t *pendingTracker) Add(clusterName string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.clusters[clusterName] = struct{}{}
+	t.gauge.Set(float64(len(t.clusters)))
+}
+
+func (t *pendingTracker) Remove(clusterName string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.clusters, clusterName)
+	t.gauge.Set(float64(len(t.clusters)))
+}
+
+// clusterSet is a set of cluster names that is safe for concurrent use.
+type clusterSet struct {
+	lock     sync.Mutex
+	clusters map[string]struct{}
+}
+
+func newClusterSet() *clusterSet {
+	return &clusterSet{
+		clusters: map[string]struct{}{},
+	}
+}
+
+// Insert adds the cluster to the set and returns false if it was already part
+// of it.
+func (s *clusterSet) Insert(clusterName string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, exists := s.clusters[clusterName]; exists {
+		return false
+	}
+
+	s.clusters[clusterName] = struct{}{}
+
+	return true
+}
+
+func (s *clusterSet) Delete(clusterName string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.clusters, clusterName)
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"container/heap"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kcp-dev/init-agent/internal/kcp"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
+
+	"k8s.io/client-go/util/workqueue"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
+)
+
+const (
+	// priorityAgingInterval is how long a workspace has to wait to gain the
+	// same precedence as one priority level. This ensures that workspaces with
+	// a low priority are eventually initialized, even if workspaces with a
+	// higher priority keep being created. Together with the range of
+	// priorities, a workspace is never overtaken by workspaces that were
+	// created more than (maxPriority-minPriority)*priorityAgingInterval after
+	// it, i.e. a little over 3 minutes.
+	priorityAgingInterval = time.Second
+
+	minPriority = -100
+	maxPriority = 100
+)
+
+// workspacePriority returns the priority of the given LogicalCluster, as
+// configured by its priority annotation. Invalid values are ignored.
+func workspacePriority(lc *kcpcorev1alpha1.LogicalCluster) int {
+	value, ok := lc.Annotations[initializationv1alpha1.PriorityAnnotation]
+	if !ok {
+		return 0
+	}
+
+	priority, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0
+	}
+
+	return clampPriority(priority)
+}
+
+// effectivePriority combines the priority of an InitTarget with the priority
+// of one of its workspaces.
+func effectivePriority(targetPriority, workspacePriority int) int {
+	return clampPriority(targetPriority + workspacePriority)
+}
+
+func clampPriority(priority int) int {
+	return min(max(priority, minPriority), maxPriority)
+}
+
+// priorityDeadline combines the time something has started waiting with its
+// priority. Since all waiting items age at the same rate, sorting by this
+// value is the same as sorting by the current effective priority, which is
+// the priority plus one for every priorityAgingInterval that has passed.
+func priorityDeadline(since time.Time, priority int) time.Time {
+	return since.Add(-time.Duration(clampPriority(priority)) * priorityAgingInterval)
+}
+
+// PriorityFunc returns the priority of a queued request.
+type PriorityFunc func(request mcreconcile.Request) int
+
+// priorityTracker remembers the priorities of the LogicalClusters seen by the
+// controller's watch. This allows the queue to order its items without
+// looking up each LogicalCluster while it holds its lock. The InitTarget's
+// priority is added to every cluster's priority, so that the queue uses the
+// same effective priority as the Limiter.
+type priorityTracker struct {
+	lock       sync.RWMutex
+	base       int
+	priorities map[string]int
+}
+
+func newPriorityTracker() *priorityTracker {
+	return &priorityTracker{
+		priorities: map[string]int{},
+	}
+}
+
+// Priority returns the effective priority of the request's cluster, i.e. its
+// last seen priority plus the InitTarget's priority. Unknown clusters are
+// treated like clusters without a priority.
+func (t *priorityTracker) Priority(request mcreconcile.Request) int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return effectivePriority(t.base, t.priorities[request.ClusterName])
+}
+
+// SetBase updates the InitTarget's priority. Since the InitTarget is only
+// fetched when a cluster is reconciled, items that are already queued keep
+// their previous priority until they are added again.
+func (t *priorityTracker) SetBase(priority int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.base = priority
+}
+
+func (t *priorityTracker) Forget(clusterName string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.priorities, clusterName)
+}
+
+func (t *priorityTracker) observe(obj ctrlruntimeclient.Object) {
+	lc, ok := obj.(*kcpcorev1alpha1.LogicalCluster)
+	if !ok {
+		return
+	}
+
+	clusterName := kcp.ClusterNameFromObject(lc).String()
+	priority := workspacePriority(lc)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if priority == 0 {
+		delete(t.priorities, clusterName)
+	} else {
+		t.priorities[clusterName] = priority
+	}
+}
+
+// Predicate returns a predicate that records the priority of every observed
+// LogicalCluster before it is queued. It does not filter any events and must
+// come before all predicates that do.
+func (t *priorityTracker) Predicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			t.observe(e.Object)
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			t.observe(e.ObjectNew)
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			t.Forget(kcp.ClusterNameFromObject(e.Object).String())
+			return true
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			t.observe(e.Object)
+			return true
+		},
+	}
+}
+
+// newPriorityQueue returns a function that creates a rate-limited workqueue
+// ordering its items by priority instead of FIFO. Deduplication, delays and
+// metrics are still handled by the regular workqueue.
+func newPriorityQueue(priorityOf PriorityFunc) func(string, workqueue.TypedRateLimiter[mcreconcile.Request]) workqueue.TypedRateLimitingInterface[mcreconcile.Request] {
+	return func(name string, rateLimiter workqueue.TypedRateLimiter[mcreconcile.Request]) workqueue.TypedRateLimitingInterface[mcreconcile.Request] {
+		return workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[mcreconcile.Request]{
+			DelayingQueue: workqueue.NewTypedDelayingQueueWithConfig(workqueue.TypedDelayingQueueConfig[mcreconcile.Request]{
+				Name: name,
+				Queue: workqueue.NewTypedWithConfig(workqueue.TypedQueueConfig[mcreconcile.Request]{
+					Name:  name,
+					Queue: newPriorityOrder(priorityOf, time.Now),
+				}),
+			}),
+		})
+	}
+}
+
+// priorityOrder implements workqueue.Queue. It is only ever accessed while
+// the workqueue holds its lock.
+type priorityOrder struct {
+	priorityOf PriorityFunc
+	now        func() time.Time
+	items      priorityHeap
+	index      map[mcreconcile.Request]*priorityItem
+}
+
+var _ workqueue.Queue[mcreconcile.Request] = &priorityOrder{}
+
+type priorityItem struct {
+	request  mcreconcile.Request
+	queued   time.Time
+	deadline time.Time
+	pos      int
+}
+
+func newPriorityOrder(priorityOf PriorityFunc, now func() time.Time) *priorityOrder {
+	return &priorityOrder{
+		priorityOf: priorityOf,
+		now:        now,
+		index:      map[mcreconcile.Request]*priorityItem{},
+	}
+}
+
+// Touch is called when a queued item is added again; its priority might have
+// changed in the meantime, but it keeps its place in terms of aging.
+func (q *priorityOrder) Touch(request mcreconcile.Request) {
+	item, ok := q.index[request]
+	if !ok {
+		return
+	}
+
+	item.deadline = priorityDeadline(item.queued, q.priorityOf(request))
+	heap.Fix(&q.items, item.pos)
+}
+
+func (q *priorityOrder) Push(request mcreconcile.Request) {
+	now := q.now()
+	item := &priorityItem{
+		request:  request,
+		queued:   now,
+		deadline: priorityDeadline(now, q.priorityOf(request)),
+	}
+
+	heap.Push(&q.items, item)
+	q.index[request] = item
+}
+
+func (q *priorityOrder) Len() int {
+	return q.items.Len()
+}
+
+func (q *priorityOrder) Pop() mcreconcile.Request {
+	item := heap.Pop(&q.items).(*priorityItem)
+	delete(q.index, item.request)
+
+	return item.request
+}
+
+// priorityHeap implements heap.Interface, with the earliest deadline first.
+// Items with the same deadline are handed out in the order they were added.
+type priorityHeap []*priorityItem
+
+func (h priorityHeap) Len() int {
+	return len(h)
+}
+
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].deadline.Equal(h[j].deadline) {
+		return h[i].queued.Before(h[j].queued)
+	}
+
+	return h[i].deadline.Before(h[j].deadline)
+}
+
+func (h priorityHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos = i
+	h[j].pos = j
+}
+
+func (h *priorityHeap) Push(x any) {
+	item := x.(*priorityItem)
+	item.pos = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *priorityHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+
+	return item
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"testing"
+	"time"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
+)
+
+func TestWorkspacePriority(t *testing.T) {
+	testcases := []struct {
+		annotation string
+		expected   int
+	}{
+		{annotation: "", expected: 0},
+		{annotation: "10", expected: 10},
+		{annotation: " -5 ", expected: -5},
+		{annotation: "high", expected: 0},
+		{annotation: "5000", expected: maxPriority},
+		{annotation: "-5000", expected: minPriority},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.annotation, func(t *testing.T) {
+			lc := &kcpcorev1alpha1.LogicalCluster{}
+			if tc.annotation != "" {
+				lc.ObjectMeta = metav1.ObjectMeta{
+					Annotations: map[string]string{
+						initializationv1alpha1.PriorityAnnotation: tc.annotation,
+					},
+				}
+			}
+
+			if priority := workspacePriority(lc); priority != tc.expected {
+				t.Fatalf("Expected priority %d, got %d.", tc.expected, priority)
+			}
+		})
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	priorities := map[string]int{}
+	now := time.Now()
+
+	order := newPriorityOrder(func(request mcreconcile.Request) int {
+		return priorities[request.ClusterName]
+	}, func() time.Time {
+		return now
+	})
+
+	push := func(clusterName string, priority int) {
+		priorities[clusterName] = priority
+		order.Push(mcreconcile.Request{ClusterName: clusterName})
+		now = now.Add(time.Millisecond)
+	}
+
+	// ci-old has waited for long enough to overtake the newer high priority
+	// workspace, but not the newest one with an even higher priority
+	push("ci-old", 0)
+	now = now.Add(3 * priorityAgingInterval)
+	push("ci", 0)
+	push("prod", 2)
+	push("interactive", 5)
+	push("ci-new", 0)
+
+	// raising the priority of a queued workspace moves it forward
+	priorities["ci-new"] = 10
+	order.Touch(mcreconcile.Request{ClusterName: "ci-new"})
+
+	expected := []string{"ci-new", "interactive", "ci-old", "prod", "ci"}
+	for _, name := range expected {
+		if popped := order.Pop().ClusterName; popped != name {
+			t.Fatalf("Expected %q, got %q.", name, popped)
+		}
+	}
+
+	if order.Len() != 0 {
+		t.Fatalf("Expected queue to be empty, but it has %d items.", order.Len())
+	}
+}
+
+func TestPriorityTracker(t *testing.T) {
+	tracker := newPriorityTracker()
+	pred := tracker.Predicate()
+	request := mcreconcile.Request{ClusterName: "cluster"}
+
+	newCluster := func(priority string) *kcpcorev1alpha1.LogicalCluster {
+		annotations := map[string]string{
+			logicalcluster.AnnotationKey: "cluster",
+		}
+		if priority != "" {
+			annotations[initializationv1alpha1.PriorityAnnotation] = priority
+		}
+
+		return &kcpcorev1alpha1.LogicalCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Annotations: annotations},
+		}
+	}
+
+	if !pred.Create(event.CreateEvent{Object: newCluster("10")}) {
+		t.Fatal("Expected the predicate to not filter any events.")
+	}
+
+	if priority := tracker.Priority(request); priority != 10 {
+		t.Fatalf("Expected priority 10, got %d.", priority)
+	}
+
+	pred.Update(event.UpdateEvent{ObjectOld: newCluster("10"), ObjectNew: newCluster("-5")})
+
+	if priority := tracker.Priority(request); priority != -5 {
+		t.Fatalf("Expected the updated priority -5, got %d.", priority)
+	}
+
+	pred.Delete(event.DeleteEvent{Object: newCluster("-5")})
+
+	if priority := tracker.Priority(request); priority != 0 {
+		t.Fatalf("Expected deleted clusters to be forgotten, got priority %d.", priority)
+	}
+}
+
+func TestPriorityTrackerAddsTargetPriority(t *testing.T) {
+	tracker := newPriorityTracker()
+	pred := tracker.Predicate()
+
+	for name, priority := range map[string]string{"high": "60", "highest": "100", "low": "-10"} {
+		pred.Create(event.CreateEvent{Object: &kcpcorev1alpha1.LogicalCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "cluster",
+				Annotations: map[string]string{
+					logicalcluster.AnnotationKey:              name,
+					initializationv1alpha1.PriorityAnnotation: priority,
+				},
+			},
+		}})
+	}
+
+	tracker.SetBase(50)
+
+	// the queue must use the same priorities as the Limiter
+	expected := map[string]int{
+		"high":    effectivePriority(50, 60),
+		"highest": effectivePriority(50, 100),
+		"low":     40,
+		"unknown": 50,
+	}
+
+	for name, priority := range expected {
+		if actual := tracker.Priority(mcreconcile.Request{ClusterName: name}); actual != priority {
+			t.Errorf("Expected priority %d for %q, got %d.", priority, name, actual)
+		}
+	}
+
+	// the sum is limited to the range of valid priorities
+	if priority := tracker.Priority(mcreconcile.Request{ClusterName: "high"}); priority != maxPriority {
+		t.Errorf("Expected priority to be limited to %d, got %d.", maxPriority, priority)
+	}
+
+	tracker.SetBase(-100)
+
+	if priority := tracker.Priority(mcreconcile.Request{ClusterName: "low"}); priority != minPriority {
+		t.Errorf("Expected priority to be limited to %d, got %d.", minPriority, priority)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"github.com/kcp-dev/init-agent/internal/initialize"
+	"github.com/kcp-dev/init-agent/internal/kcp"
+	"github.com/kcp-dev/init-agent/internal/manifest"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+)
+
+// newProvenance describes the objects of the idx-th init source of the given
+// InitTarget, to be recorded on all objects created from the source.
+func newProvenance(target *initializationv1alpha1.InitTarget, idx int, ref initializationv1alpha1.InitSource, src initialize.ManifestsSource, managedBy string) *manifest.Provenance {
+	provenance := &manifest.Provenance{
+		ManagedBy:         managedBy,
+		InitTargetCluster: kcp.ClusterNameFromObject(target).String(),
+		InitTarget:        target.Name,
+		SourceIndex:       idx,
+	}
+
+	if ref.Template != nil {
+		provenance.SourceTemplate = ref.Template.Name
+	}
+
+	if versioned, ok := src.(initialize.VersionedSource); ok {
+		provenance.SourceVersion = versioned.Version()
+	}
+
+	return provenance
+}
@@ -18,26 +18,51 @@ package initcontroller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/kcp-dev/init-agent/internal/initialize"
 	"github.com/kcp-dev/init-agent/internal/kcp"
 	"github.com/kcp-dev/init-agent/internal/log"
+	"github.com/kcp-dev/init-agent/internal/manifest"
+	"github.com/kcp-dev/init-agent/internal/metrics"
+	"github.com/kcp-dev/init-agent/internal/tracing"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
 
 	"github.com/kcp-dev/logicalcluster/v3"
 	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
 )
 
 func (r *Reconciler) Reconcile(ctx context.Context, request mcreconcile.Request) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "initcontroller.Reconcile", trace.WithAttributes(
+		attribute.String("cluster", request.ClusterName),
+		attribute.String("initializer", string(r.initializer)),
+	))
+	defer span.End()
+
+	result, err := r.reconcileCluster(ctx, request)
+
+	return result, tracing.RecordError(span, err)
+}
+
+func (r *Reconciler) reconcileCluster(ctx context.Context, request mcreconcile.Request) (reconcile.Result, error) {
 	// No need to include the request in the context, it's just "/cluster" for every
 	// single reconciliation anyway.
 	logger := r.log.With("dest-cluster", request.ClusterName)
@@ -56,15 +81,40 @@ func (r *Reconciler) Reconcile(ctx context.Context, request mcreconcile.Request)
 
 	// object was not found anymore
 	if lc.GetName() == "" {
+		r.apiWaiter.Forget(request.ClusterName)
+		r.pending.Remove(request.ClusterName)
+		r.exhausted.Delete(request.ClusterName)
+		r.suspended.Delete(request.ClusterName)
+		r.priorities.Forget(request.ClusterName)
+		r.shards.Forget(request.ClusterName)
 		return reconcile.Result{}, nil
 	}
 
 	// we're already done (in this case, the cluster should not have been visible
 	// in the virtual workspace anymore)
 	if !slices.Contains(lc.Status.Initializers, r.initializer) {
+		r.apiWaiter.Forget(request.ClusterName)
+		r.pending.Remove(request.ClusterName)
+		r.exhausted.Delete(request.ClusterName)
+		r.suspended.Delete(request.ClusterName)
+		r.priorities.Forget(request.ClusterName)
+		r.shards.Forget(request.ClusterName)
 		return reconcile.Result{}, nil
 	}
 
+	// another replica is responsible for this cluster
+	if !r.shards.Owns(request.ClusterName) {
+		logger.Debug("Cluster belongs to another shard, skipping")
+		r.apiWaiter.Forget(request.ClusterName)
+		r.pending.Remove(request.ClusterName)
+		r.exhausted.Delete(request.ClusterName)
+		r.suspended.Delete(request.ClusterName)
+		r.priorities.Forget(request.ClusterName)
+		return reconcile.Result{}, nil
+	}
+
+	r.pending.Add(request.ClusterName)
+
 	workspace := kcp.ClusterPathFromObject(lc)
 	logger = logger.With("dest-workspace", workspace)
 
@@ -72,66 +122,378 @@ func (r *Reconciler) Reconcile(ctx context.Context, request mcreconcile.Request)
 	ctx = initialize.WithWorkspacePath(ctx, workspace)
 	ctx = log.WithLog(ctx, logger)
 
-	requeue, err := r.reconcile(ctx, logger, client, lc)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("workspace", workspace.String()))
+
+	// Dynamically fetch the latest InitTarget, so that we do not have to restart
+	// (and re-cache) this controller everytime an InitTarget changes.
+	target, err := r.targetProvider(ctx)
 	if err != nil {
-		recorder := cluster.GetEventRecorderFor(ControllerName)
-		recorder.Eventf(lc, corev1.EventTypeWarning, "ReconcilingFailed", "Failed to initialize cluster: %s.", err)
+		return reconcile.Result{}, fmt.Errorf("failed to get InitTarget: %w", err)
+	}
+
+	r.priorities.SetBase(int(target.Spec.Priority))
+
+	// the initialization has failed permanently, do not retry anymore
+	if hasListAnnotation(lc, initializationv1alpha1.FailedAnnotation, r.initializer) {
+		logger.Debug("Initialization has failed permanently, skipping")
+		r.apiWaiter.Forget(request.ClusterName)
+		return reconcile.Result{}, nil
+	}
 
+	recorder := cluster.GetEventRecorderFor(ControllerName)
+
+	// Keep the workspace blocked, but do not touch it until the InitTarget is
+	// resumed. There is no watch on InitTargets here, so check back regularly.
+	if target.Spec.Suspend {
+		logger.Debug("InitTarget is suspended, skipping")
+		r.recordSuspended(recorder, lc, request.ClusterName)
+
+		return reconcile.Result{RequeueAfter: suspendedRequeueInterval}, nil
+	}
+
+	r.suspended.Delete(request.ClusterName)
+
+	defaults := r.opts.Settings.Get()
+	policy := newRetryPolicy(target.Spec, defaults.RetryInitialDelay, defaults.RetryMaxDelay)
+	now := time.Now()
+
+	// Do not overload kcp when lots of workspaces are created at once; the
+	// deadline keeps running while waiting for a free slot.
+	priority := effectivePriority(int(target.Spec.Priority), workspacePriority(lc))
+	if err := r.opts.Limiter.Acquire(ctx, priority); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	res := reconcile.Result{}
-	if requeue {
-		res.RequeueAfter = 5 * time.Second
+	metrics.InitializationsInFlight.Inc()
+	status, err := r.reconcile(ctx, logger, cluster, recorder, lc, target)
+	metrics.InitializationsInFlight.Dec()
+	r.opts.Limiter.Release()
+
+	if r.reporter != nil && (err != nil || status == statusComplete) {
+		r.reporter(workspace, err)
+	}
+
+	if err != nil {
+		recorder.Eventf(lc, corev1.EventTypeWarning, "ReconcilingFailed", "Failed to initialize cluster: %s.", err)
+
+		return r.handleFailure(ctx, logger, client, recorder, lc, target, policy, err)
+	}
+
+	switch status {
+	case statusComplete:
+		if err := setAttempts(ctx, client, lc, r.initializer, 0); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		return reconcile.Result{}, r.removeInitializer(ctx, logger, client, lc, target, true)
+
+	case statusRequeue:
+		if policy.deadlineExceeded(lc, now) {
+			return r.applyFailurePolicy(ctx, logger, client, recorder, lc, target, policy, errors.New("deadline exceeded before all objects were ready"))
+		}
+
+		return reconcile.Result{RequeueAfter: policy.initialDelay}, nil
+
+	default: // statusWaiting
+		if policy.deadlineExceeded(lc, now) {
+			return r.applyFailurePolicy(ctx, logger, client, recorder, lc, target, policy, errors.New("deadline exceeded while waiting for APIs"))
+		}
+
+		// The apiWaiter will requeue the cluster once the APIs are available,
+		// but make sure to come back in time to enforce the deadline.
+		return reconcile.Result{RequeueAfter: policy.untilDeadline(lc, now)}, nil
 	}
+}
 
-	return res, nil
+// recordSuspended emits an event when a cluster is first found to be blocked by
+// a suspended InitTarget. The cluster is checked again regularly while the
+// suspension lasts, but that is already visible in the InitTarget's Suspended
+// condition and not worth an event every time.
+func (r *Reconciler) recordSuspended(recorder record.EventRecorder, lc *kcpcorev1alpha1.LogicalCluster, clusterName string) {
+	if r.suspended.Insert(clusterName) {
+		recorder.Event(lc, corev1.EventTypeNormal, "InitializationSuspended", "Initialization is paused because the InitTarget is suspended.")
+	}
 }
 
-func (r *Reconciler) reconcile(ctx context.Context, logger *zap.SugaredLogger, client ctrlruntimeclient.Client, lc *kcpcorev1alpha1.LogicalCluster) (requeue bool, err error) {
-	// Dynamically fetch the latest InitTarget, so that we do not have to restart
-	// (and re-cache) this controller everytime an InitTarget changes.
-	target, err := r.targetProvider(ctx)
-	if err != nil {
-		return requeue, fmt.Errorf("failed to get InitTarget: %w", err)
+type reconcileStatus int
+
+const (
+	// statusComplete means all sources have been applied and the initializer
+	// can be removed.
+	statusComplete reconcileStatus = iota
+	// statusRequeue means that some objects are not yet ready.
+	statusRequeue
+	// statusWaiting means that some APIs are not yet available; the apiWaiter
+	// will requeue the cluster.
+	statusWaiting
+)
+
+func (r *Reconciler) reconcile(ctx context.Context, logger *zap.SugaredLogger, cluster cluster.Cluster, recorder record.EventRecorder, lc *kcpcorev1alpha1.LogicalCluster, target *initializationv1alpha1.InitTarget) (reconcileStatus, error) {
+	client := cluster.GetClient()
+
+	applyOpts := manifest.ApplyOptions{
+		ContinueOnError: r.opts.ContinueOnError,
+	}
+	if len(target.Spec.KindOrder) > 0 {
+		var err error
+		applyOpts.Sorter, err = manifest.NewSorter(target.Spec.KindOrder)
+		if err != nil {
+			return statusRequeue, fmt.Errorf("invalid kind order in InitTarget: %w", err)
+		}
 	}
 
+	var (
+		errs    []error
+		missing []schema.GroupVersionKind
+		requeue bool
+	)
+
 	for idx, ref := range target.Spec.Sources {
 		sourceLog := logger.With("init-target", target.Name, "source-idx", idx)
 		sourceCtx := log.WithLog(ctx, sourceLog)
 
 		src, err := r.sourceFactory.NewForInitSource(sourceCtx, kcp.ClusterNameFromObject(target), ref)
 		if err != nil {
-			return requeue, fmt.Errorf("failed to initialize source #%d: %w", idx, err)
+			return statusRequeue, fmt.Errorf("failed to initialize source #%d: %w", idx, err)
 		}
 
-		objects, err := src.Manifests(lc)
+		renderStart := time.Now()
+		objects, err := src.Manifests(sourceCtx, lc)
+		metrics.SourceRenderDuration.WithLabelValues(r.opts.InitTarget, strconv.Itoa(idx)).Observe(time.Since(renderStart).Seconds())
 		if err != nil {
-			return requeue, fmt.Errorf("failed to render source #%d: %w", idx, err)
+			return statusRequeue, fmt.Errorf("failed to render source #%d: %w", idx, err)
 		}
 
 		sourceLog.Debugf("Source yielded %d manifests", len(objects))
 
-		srcNeedRequeue, err := r.manifestApplier.Apply(sourceCtx, client, objects)
+		transformer, err := manifest.NewTransformer(target.Spec.Transformations, ref.Transformations)
 		if err != nil {
-			return requeue, fmt.Errorf("failed to apply source #%d: %w", idx, err)
+			return statusRequeue, fmt.Errorf("invalid transformations for source #%d: %w", idx, err)
+		}
+
+		if err := transformer.Transform(sourceCtx, objects, cluster.GetRESTMapper()); err != nil {
+			return statusRequeue, fmt.Errorf("failed to transform source #%d: %w", idx, err)
+		}
+
+		sourceOpts := applyOpts
+		if r.opts.Provenance {
+			sourceOpts.Provenance = newProvenance(target, idx, ref, src, r.opts.ManagedBy)
+		}
+
+		applyStart := time.Now()
+		result, err := r.manifestApplier.Apply(sourceCtx, client, objects, sourceOpts)
+		metrics.SourceApplyDuration.WithLabelValues(r.opts.InitTarget, strconv.Itoa(idx)).Observe(time.Since(applyStart).Seconds())
+		if err != nil {
+			return statusRequeue, fmt.Errorf("failed to apply source #%d: %w", idx, err)
+		}
+
+		logApplyResult(sourceLog, result)
+		recordApplyResult(result)
+		recordApplyEvent(recorder, lc, idx, result)
+
+		if err := result.Err(); err != nil {
+			err = fmt.Errorf("failed to apply source #%d (%s): %w", idx, result.Summary(), err)
+
+			if !r.opts.ContinueOnError {
+				return statusRequeue, err
+			}
+
+			// continue with the remaining sources and report all errors at once
+			errs = append(errs, err)
+			continue
+		}
+
+		// Sources waiting for APIs do not need to be requeued periodically, the
+		// apiWaiter will requeue the cluster once the APIs are available.
+		if kinds := result.MissingKinds(); len(kinds) > 0 {
+			sourceLog.Debugw("Source is waiting for APIs", "kinds", kinds)
+			for _, kind := range kinds {
+				if !slices.Contains(missing, kind) {
+					missing = append(missing, kind)
+				}
+			}
+			continue
 		}
 
 		// If one source cannot be completed at this time, continue with the others.
-		if srcNeedRequeue {
+		if result.Requeue() {
 			sourceLog.Debug("Source requires requeuing")
 			requeue = true
 		}
 	}
 
-	if !requeue {
-		return false, r.removeInitializer(ctx, logger, client, lc)
+	if len(errs) > 0 {
+		return statusRequeue, utilerrors.NewAggregate(errs)
 	}
 
-	return requeue, nil
+	clusterName := initialize.ClusterFromContext(ctx).String()
+
+	if len(missing) > 0 {
+		if timedOut := r.apiWaiter.Wait(clusterName, cluster.GetRESTMapper(), missing); len(timedOut) > 0 {
+			return statusRequeue, fmt.Errorf("timed out waiting for %s to become available", formatKinds(timedOut))
+		}
+
+		recorder.Eventf(lc, corev1.EventTypeNormal, "WaitingForAPI", "Waiting for %s to become available.", formatKinds(missing))
+
+		return statusWaiting, nil
+	}
+
+	r.apiWaiter.Forget(clusterName)
+
+	if requeue {
+		return statusRequeue, nil
+	}
+
+	return statusComplete, nil
+}
+
+// handleFailure records a failed attempt and either schedules the next attempt
+// or applies the InitTarget's failure policy once the retry policy is exhausted.
+func (r *Reconciler) handleFailure(ctx context.Context, logger *zap.SugaredLogger, client ctrlruntimeclient.Client, recorder record.EventRecorder, lc *kcpcorev1alpha1.LogicalCluster, target *initializationv1alpha1.InitTarget, policy retryPolicy, reconcileErr error) (reconcile.Result, error) {
+	metrics.FailedAttempts.WithLabelValues(r.opts.InitTarget).Inc()
+
+	attempts := getAttempts(lc)[string(r.initializer)] + 1
+	if err := setAttempts(ctx, client, lc, r.initializer, attempts); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !policy.exhausted(lc, attempts, time.Now()) {
+		delay := policy.delay(attempts)
+		logger.Infow("Initialization failed, will retry", "attempt", attempts, "delay", delay, "error", reconcileErr)
+
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
+
+	return r.applyFailurePolicy(ctx, logger, client, recorder, lc, target, policy, fmt.Errorf("giving up after %d attempts: %w", attempts, reconcileErr))
 }
 
-func (r *Reconciler) removeInitializer(ctx context.Context, log *zap.SugaredLogger, client ctrlruntimeclient.Client, lc *kcpcorev1alpha1.LogicalCluster) error {
+func (r *Reconciler) applyFailurePolicy(ctx context.Context, logger *zap.SugaredLogger, client ctrlruntimeclient.Client, recorder record.EventRecorder, lc *kcpcorev1alpha1.LogicalCluster, target *initializationv1alpha1.InitTarget, policy retryPolicy, reason error) (reconcile.Result, error) {
+	logger = logger.With("failure-policy", policy.failurePolicy)
+
+	// Count every workspace only once, even if the failure policy keeps
+	// being applied (e.g. while blocking).
+	firstTime := r.exhausted.Insert(initialize.ClusterFromContext(ctx).String())
+	if firstTime {
+		metrics.InitializationFailures.WithLabelValues(r.opts.InitTarget).Inc()
+	}
+
+	switch policy.failurePolicy {
+	case initializationv1alpha1.FailurePolicyRemoveInitializer:
+		logger.Warnw("Retries exhausted, removing initializer anyway", "reason", reason)
+
+		if err := addToListAnnotation(ctx, client, lc, initializationv1alpha1.PartiallyInitializedAnnotation, r.initializer); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		recorder.Eventf(lc, corev1.EventTypeWarning, "PartiallyInitialized", "Removing initializer even though initialization did not succeed: %s.", reason)
+
+		r.apiWaiter.Forget(initialize.ClusterFromContext(ctx).String())
+
+		return reconcile.Result{}, r.removeInitializer(ctx, logger, client, lc, target, false)
+
+	case initializationv1alpha1.FailurePolicyFail:
+		logger.Warnw("Retries exhausted, marking initialization as failed", "reason", reason)
+
+		if err := addToListAnnotation(ctx, client, lc, initializationv1alpha1.FailedAnnotation, r.initializer); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		recorder.Eventf(lc, corev1.EventTypeWarning, "InitializationFailed", "Initialization failed permanently: %s.", reason)
+
+		r.apiWaiter.Forget(initialize.ClusterFromContext(ctx).String())
+
+		return reconcile.Result{}, nil
+
+	default:
+		logger.Warnw("Retries exhausted, continuing to retry with maximum delay", "reason", reason)
+
+		if firstTime {
+			recorder.Eventf(lc, corev1.EventTypeWarning, "RetriesExhausted", "Initialization keeps failing, retrying every %s: %s.", policy.maxDelay, reason)
+		}
+
+		return reconcile.Result{RequeueAfter: policy.maxDelay}, nil
+	}
+}
+
+func formatKinds(kinds []schema.GroupVersionKind) string {
+	names := make([]string, len(kinds))
+	for i, kind := range kinds {
+		names[i] = kind.GroupKind().String() + " " + kind.Version
+	}
+
+	prefix := "API"
+	if len(names) > 1 {
+		prefix = "APIs"
+	}
+
+	return fmt.Sprintf("%s %s", prefix, strings.Join(names, ", "))
+}
+
+func logApplyResult(log *zap.SugaredLogger, result *manifest.Result) {
+	log.Debugw("Applied source", "summary", result.Summary())
+
+	for _, obj := range result.Objects {
+		switch obj.Status {
+		case manifest.ObjectCreated, manifest.ObjectExisted:
+			log.Debugw("Object applied", "object", obj.Object.String(), "status", obj.Status)
+		case manifest.ObjectFailed, manifest.ObjectDeferred, manifest.ObjectSkipped:
+			log.Infow("Object could not be applied", "object", obj.Object.String(), "status", obj.Status, "reason", obj.Reason)
+		}
+	}
+
+	for _, obj := range result.NotReady {
+		log.Debugw("Object is not yet ready", "object", obj.String())
+	}
+}
+
+// maxEventObjects is the maximum number of objects listed in a single event.
+const maxEventObjects = 10
+
+// recordApplyEvent emits a single event listing all objects of a source that
+// could not be applied, if there are any.
+func recordApplyEvent(recorder record.EventRecorder, lc *kcpcorev1alpha1.LogicalCluster, idx int, result *manifest.Result) {
+	var problems []string
+	for _, obj := range result.Objects {
+		switch obj.Status {
+		case manifest.ObjectFailed, manifest.ObjectDeferred, manifest.ObjectSkipped:
+			problems = append(problems, fmt.Sprintf("%s %s (%s)", obj.Object, strings.ToLower(string(obj.Status)), obj.Reason))
+		}
+	}
+
+	if len(problems) == 0 {
+		return
+	}
+
+	if len(problems) > maxEventObjects {
+		problems = append(problems[:maxEventObjects], fmt.Sprintf("and %d more", len(problems)-maxEventObjects))
+	}
+
+	eventType := corev1.EventTypeNormal
+	if result.Count(manifest.ObjectFailed) > 0 {
+		eventType = corev1.EventTypeWarning
+	}
+
+	recorder.Eventf(lc, eventType, "ObjectsNotApplied", "Source #%d (%s): %s.", idx, result.Summary(), strings.Join(problems, "; "))
+}
+
+func recordApplyResult(result *manifest.Result) {
+	for _, obj := range result.Objects {
+		switch obj.Status {
+		case manifest.ObjectCreated:
+			metrics.ObjectApplied(obj.Object.GroupVersionKind, "created")
+		case manifest.ObjectExisted:
+			metrics.ObjectApplied(obj.Object.GroupVersionKind, "existed")
+		}
+	}
+}
+
+// removeInitializer removes this controller's initializer from the cluster.
+// Only successfully initialized clusters are counted as initialized, not
+// those that the RemoveInitializer failure policy gave up on.
+func (r *Reconciler) removeInitializer(ctx context.Context, log *zap.SugaredLogger, client ctrlruntimeclient.Client, lc *kcpcorev1alpha1.LogicalCluster, target *initializationv1alpha1.InitTarget, initialized bool) error {
+	ctx, span := tracing.Start(ctx, "initcontroller.removeInitializer")
+	defer span.End()
+
 	oldCluster := lc.DeepCopy()
 
 	lc.Status.Initializers = slices.DeleteFunc(lc.Status.Initializers, func(i kcpcorev1alpha1.LogicalClusterInitializer) bool {
@@ -141,10 +503,23 @@ func (r *Reconciler) removeInitializer(ctx context.Context, log *zap.SugaredLogg
 	if len(lc.Status.Initializers) != len(oldCluster.Status.Initializers) {
 		log.Debugw("Removing initializer from cluster", "initializer", r.initializer)
 		if err := client.Status().Patch(ctx, lc, ctrlruntimeclient.MergeFrom(oldCluster)); err != nil {
-			return fmt.Errorf("failed to remove initializer: %w", err)
+			return tracing.RecordError(span, fmt.Errorf("failed to remove initializer: %w", err))
+		}
+
+		if initialized {
+			log.Info("Cluster successfully initialized")
+
+			metrics.WorkspacesInitialized.WithLabelValues(r.opts.InitTarget).Inc()
+			metrics.InitializationDuration.WithLabelValues(r.opts.InitTarget).Observe(time.Since(lc.CreationTimestamp.Time).Seconds())
+		} else {
+			log.Info("Cluster partially initialized")
 		}
-		log.Info("Cluster successfully initialized")
 	}
 
+	r.pending.Remove(initialize.ClusterFromContext(ctx).String())
+	r.exhausted.Delete(initialize.ClusterFromContext(ctx).String())
+	r.suspended.Delete(initialize.ClusterFromContext(ctx).String())
+	r.priorities.Forget(initialize.ClusterFromContext(ctx).String())
+
 	return nil
 }
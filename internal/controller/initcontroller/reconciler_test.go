@@ -0,0 +1,395 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+
+	"github.com/kcp-dev/init-agent/internal/initialize/source"
+	"github.com/kcp-dev/init-agent/internal/initialize/source/inittemplate"
+	"github.com/kcp-dev/init-agent/internal/manifest"
+	"github.com/kcp-dev/init-agent/internal/metrics"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+	kcpcore "github.com/kcp-dev/sdk/apis/core"
+	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	mcmanager "sigs.k8s.io/multicluster-runtime/pkg/manager"
+	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
+)
+
+func TestRecordApplyEvent(t *testing.T) {
+	configMap := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+	result := &manifest.Result{
+		Objects: []manifest.ObjectResult{
+			{Object: manifest.ObjectReference{GroupVersionKind: configMap, Namespace: "default", Name: "ok"}, Status: manifest.ObjectCreated},
+			{Object: manifest.ObjectReference{GroupVersionKind: configMap, Namespace: "default", Name: "broken"}, Status: manifest.ObjectFailed, Reason: "boom", Error: errors.New("boom")},
+			{Object: manifest.ObjectReference{GroupVersionKind: configMap, Namespace: "broken", Name: "dependent"}, Status: manifest.ObjectSkipped, Reason: "depends on configmap default/broken"},
+		},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	recordApplyEvent(recorder, &kcpcorev1alpha1.LogicalCluster{}, 1, result)
+
+	if len(recorder.Events) != 1 {
+		t.Fatalf("Expected exactly one event, got %d.", len(recorder.Events))
+	}
+
+	event := <-recorder.Events
+	for _, expected := range []string{"Warning ObjectsNotApplied", "Source #1", "configmap default/broken failed (boom)", "configmap broken/dependent skipped"} {
+		if !strings.Contains(event, expected) {
+			t.Errorf("Expected event %q to contain %q.", event, expected)
+		}
+	}
+
+	if strings.Contains(event, "default/ok") {
+		t.Errorf("Expected event %q not to list applied objects.", event)
+	}
+
+	// nothing to report
+	recordApplyEvent(recorder, &kcpcorev1alpha1.LogicalCluster{}, 0, &manifest.Result{Objects: result.Objects[:1]})
+	if len(recorder.Events) != 0 {
+		t.Errorf("Expected no event for a successful source, got %d.", len(recorder.Events))
+	}
+}
+
+func TestSuspendedEventIsRecordedOnce(t *testing.T) {
+	r := &Reconciler{suspended: newClusterSet()}
+	lc := &kcpcorev1alpha1.LogicalCluster{}
+	recorder := record.NewFakeRecorder(10)
+
+	for range 3 {
+		r.recordSuspended(recorder, lc, "cluster")
+	}
+
+	if events := len(recorder.Events); events != 1 {
+		t.Fatalf("Expected a single event while the InitTarget is suspended, got %d.", events)
+	}
+
+	// resuming the InitTarget forgets the cluster, so a later suspension is
+	// reported again
+	r.suspended.Delete("cluster")
+	r.recordSuspended(recorder, lc, "cluster")
+
+	if events := len(recorder.Events); events != 2 {
+		t.Fatalf("Expected another event after the InitTarget was suspended again, got %d.", events)
+	}
+}
+
+// fakeRemoteManager serves a single workspace to initialize.
+type fakeRemoteManager struct {
+	mcmanager.Manager
+	cluster cluster.Cluster
+}
+
+func (m *fakeRemoteManager) GetCluster(context.Context, string) (cluster.Cluster, error) {
+	return m.cluster, nil
+}
+
+type fakeCluster struct {
+	cluster.Cluster
+	client   ctrlruntimeclient.Client
+	recorder *record.FakeRecorder
+}
+
+func (c *fakeCluster) GetClient() ctrlruntimeclient.Client {
+	return c.client
+}
+
+func (c *fakeCluster) GetRESTMapper() meta.RESTMapper {
+	return meta.NewDefaultRESTMapper(nil)
+}
+
+func (c *fakeCluster) GetEventRecorderFor(string) record.EventRecorder {
+	return c.recorder
+}
+
+// fakeTemplateClient serves InitTemplates from the config workspace.
+type fakeTemplateClient struct {
+	client ctrlruntimeclient.Client
+}
+
+func (c *fakeTemplateClient) Cluster(logicalcluster.Name, *runtime.Scheme) (ctrlruntimeclient.Client, error) {
+	return c.client, nil
+}
+
+func (c *fakeTemplateClient) ClusterConfig(logicalcluster.Name) *rest.Config {
+	return &rest.Config{}
+}
+
+// createdApplier reports every object as created without applying it.
+type createdApplier struct {
+	applied int
+}
+
+func (a *createdApplier) Apply(_ context.Context, _ ctrlruntimeclient.Client, objs []*unstructured.Unstructured, _ manifest.ApplyOptions) (*manifest.Result, error) {
+	a.applied += len(objs)
+
+	result := &manifest.Result{}
+	for _, obj := range objs {
+		result.Objects = append(result.Objects, manifest.ObjectResult{
+			Object: manifest.ObjectReference{GroupVersionKind: obj.GroupVersionKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()},
+			Status: manifest.ObjectCreated,
+		})
+	}
+
+	return result, nil
+}
+
+// newFakeReconciler returns a reconciler for the initializer "root:a" and
+// the given InitTarget, which initializes a single LogicalCluster "cluster"
+// using a createdApplier.
+func newFakeReconciler(t *testing.T, initTarget string, target *initializationv1alpha1.InitTarget) (*Reconciler, ctrlruntimeclient.Client) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := kcpcorev1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed to build scheme: %v", err)
+	}
+	if err := initializationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed to build scheme: %v", err)
+	}
+
+	lc := &kcpcorev1alpha1.LogicalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cluster",
+			Annotations: map[string]string{
+				kcpcore.LogicalClusterPathAnnotationKey: "root:my-ws",
+			},
+		},
+		Status: kcpcorev1alpha1.LogicalClusterStatus{
+			Initializers: []kcpcorev1alpha1.LogicalClusterInitializer{"root:a"},
+		},
+	}
+
+	template := &initializationv1alpha1.InitTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-template"},
+		Spec: initializationv1alpha1.InitTemplateSpec{
+			Template: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: hello\n  namespace: default\n",
+		},
+	}
+
+	workspaceClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lc).WithStatusSubresource(lc).Build()
+	configClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).Build()
+
+	r := &Reconciler{
+		remoteManager: &fakeRemoteManager{cluster: &fakeCluster{client: workspaceClient, recorder: record.NewFakeRecorder(100)}},
+		targetProvider: func(context.Context) (*initializationv1alpha1.InitTarget, error) {
+			return target, nil
+		},
+		log: zap.NewNop().Sugar(),
+		sourceFactory: source.NewFactory(source.Dependencies{
+			Template: inittemplate.Dependencies{ClusterClient: &fakeTemplateClient{client: configClient}},
+		}),
+		manifestApplier: &createdApplier{},
+		initializer:     "root:a",
+		opts:            Options{InitTarget: initTarget},
+		apiWaiter:       newAPIWaiter(0),
+		pending:         newPendingTracker(metrics.PendingWorkspaces.WithLabelValues("root:a")),
+		exhausted:       newClusterSet(),
+		suspended:       newClusterSet(),
+		priorities:      newPriorityTracker(),
+		shards:          newShardTracker(nil),
+	}
+
+	return r, workspaceClient
+}
+
+func newFakeTarget(name string) *initializationv1alpha1.InitTarget {
+	return &initializationv1alpha1.InitTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey: "config",
+			},
+		},
+		Spec: initializationv1alpha1.InitTargetSpec{
+			Sources: []initializationv1alpha1.InitSource{{
+				Template: &initializationv1alpha1.TemplateInitSource{Name: "my-template"},
+			}},
+		},
+	}
+}
+
+func reconcileCluster(t *testing.T, r *Reconciler) reconcile.Result {
+	t.Helper()
+
+	request := mcreconcile.Request{
+		ClusterName: "cluster",
+		Request:     reconcile.Request{NamespacedName: types.NamespacedName{Name: "cluster"}},
+	}
+
+	result, err := r.Reconcile(t.Context(), request)
+	if err != nil {
+		t.Fatalf("Reconciling failed: %v", err)
+	}
+
+	return result
+}
+
+func getCluster(t *testing.T, client ctrlruntimeclient.Client) *kcpcorev1alpha1.LogicalCluster {
+	t.Helper()
+
+	lc := &kcpcorev1alpha1.LogicalCluster{}
+	if err := client.Get(t.Context(), types.NamespacedName{Name: "cluster"}, lc); err != nil {
+		t.Fatalf("Failed to get LogicalCluster: %v", err)
+	}
+
+	return lc
+}
+
+func TestReconcileRecordsMetrics(t *testing.T) {
+	// the targetcontroller identifies InitTargets as "<workspace>/<name>"
+	const initTarget = "root:config/metrics"
+
+	t.Run("successful initialization", func(t *testing.T) {
+		r, client := newFakeReconciler(t, initTarget, newFakeTarget("metrics"))
+
+		configMaps := metrics.ObjectsApplied.WithLabelValues("", "v1", "ConfigMap", "created")
+		appliedBefore := testutil.ToFloat64(configMaps)
+		initializedBefore := testutil.ToFloat64(metrics.WorkspacesInitialized.WithLabelValues(initTarget))
+
+		reconcileCluster(t, r)
+
+		lc := getCluster(t, client)
+
+		if slices.Contains(lc.Status.Initializers, "root:a") {
+			t.Fatal("Expected the initializer to be removed.")
+		}
+
+		if applied := testutil.ToFloat64(configMaps) - appliedBefore; applied != 1 {
+			t.Errorf("Expected one created ConfigMap to be counted, got %v.", applied)
+		}
+
+		if initialized := testutil.ToFloat64(metrics.WorkspacesInitialized.WithLabelValues(initTarget)) - initializedBefore; initialized != 1 {
+			t.Errorf("Expected one initialized workspace to be counted for %q, got %v.", initTarget, initialized)
+		}
+
+		// an InitTarget with the same name in another workspace is unaffected
+		if initialized := testutil.ToFloat64(metrics.WorkspacesInitialized.WithLabelValues("root:other/metrics")); initialized != 0 {
+			t.Errorf("Expected no initialized workspaces for an equally named InitTarget, got %v.", initialized)
+		}
+	})
+
+	t.Run("failed initialization", func(t *testing.T) {
+		target := newFakeTarget("metrics")
+		// an invalid kind order fails every attempt
+		target.Spec.KindOrder = []string{""}
+		target.Spec.RetryPolicy = &initializationv1alpha1.RetryPolicy{MaxAttempts: ptr.To[int32](2)}
+		target.Spec.FailurePolicy = initializationv1alpha1.FailurePolicyFail
+
+		r, client := newFakeReconciler(t, initTarget, target)
+
+		attemptsBefore := testutil.ToFloat64(metrics.FailedAttempts.WithLabelValues(initTarget))
+		failuresBefore := testutil.ToFloat64(metrics.InitializationFailures.WithLabelValues(initTarget))
+		initializedBefore := testutil.ToFloat64(metrics.WorkspacesInitialized.WithLabelValues(initTarget))
+
+		// the second attempt exhausts the retry policy, afterwards the
+		// workspace is skipped
+		for range 3 {
+			reconcileCluster(t, r)
+		}
+
+		lc := getCluster(t, client)
+
+		if !hasListAnnotation(lc, initializationv1alpha1.FailedAnnotation, "root:a") {
+			t.Fatal("Expected the initialization to be marked as failed.")
+		}
+
+		if attempts := testutil.ToFloat64(metrics.FailedAttempts.WithLabelValues(initTarget)) - attemptsBefore; attempts != 2 {
+			t.Errorf("Expected 2 failed attempts to be counted for %q, got %v.", initTarget, attempts)
+		}
+
+		if failures := testutil.ToFloat64(metrics.InitializationFailures.WithLabelValues(initTarget)) - failuresBefore; failures != 1 {
+			t.Errorf("Expected one failed workspace to be counted for %q, got %v.", initTarget, failures)
+		}
+
+		if initialized := testutil.ToFloat64(metrics.WorkspacesInitialized.WithLabelValues(initTarget)) - initializedBefore; initialized != 0 {
+			t.Errorf("Expected the failed workspace not to be counted as initialized, got %v.", initialized)
+		}
+	})
+}
+
+func TestSuspendedInitTargetIsResumed(t *testing.T) {
+	target := newFakeTarget("suspended")
+	target.Spec.Suspend = true
+
+	r, client := newFakeReconciler(t, "root:config/suspended", target)
+	applier := r.manifestApplier.(*createdApplier)
+	recorder := r.remoteManager.(*fakeRemoteManager).cluster.(*fakeCluster).recorder
+
+	for range 2 {
+		result := reconcileCluster(t, r)
+
+		if result.RequeueAfter != suspendedRequeueInterval {
+			t.Errorf("Expected the workspace to be checked again after %v, got %v.", suspendedRequeueInterval, result.RequeueAfter)
+		}
+	}
+
+	if applier.applied != 0 {
+		t.Fatalf("Expected no objects to be applied while the InitTarget is suspended, got %d.", applier.applied)
+	}
+
+	if !slices.Contains(getCluster(t, client).Status.Initializers, "root:a") {
+		t.Fatal("Expected the initializer to be kept while the InitTarget is suspended.")
+	}
+
+	if events := len(recorder.Events); events != 1 {
+		t.Fatalf("Expected a single event while the InitTarget is suspended, got %d.", events)
+	}
+
+	if event := <-recorder.Events; !strings.Contains(event, "InitializationSuspended") {
+		t.Fatalf("Expected an InitializationSuspended event, got %q.", event)
+	}
+
+	// resuming the InitTarget continues the initialization
+	target.Spec.Suspend = false
+
+	if result := reconcileCluster(t, r); !result.IsZero() {
+		t.Errorf("Expected the initialized workspace not to be requeued, got %+v.", result)
+	}
+
+	if applier.applied != 1 {
+		t.Fatalf("Expected the template to be applied after resuming, got %d applied objects.", applier.applied)
+	}
+
+	if slices.Contains(getCluster(t, client).Status.Initializers, "root:a") {
+		t.Fatal("Expected the initializer to be removed after resuming.")
+	}
+}
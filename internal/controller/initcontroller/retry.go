@@ -0,0 +1,204 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
+
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultInitialRetryDelay = 5 * time.Second
+	defaultMaxRetryDelay     = 5 * time.Minute
+)
+
+// retryPolicy is the effective retry policy of an InitTarget, with all
+// defaults applied.
+type retryPolicy struct {
+	initialDelay  time.Duration
+	maxDelay      time.Duration
+	maxAttempts   int
+	deadline      time.Duration
+	failurePolicy initializationv1alpha1.FailurePolicy
+}
+
+// newRetryPolicy returns the retry policy for the given InitTarget. The
+// default delays are used if the InitTarget does not configure them; if they
+// are zero, the built-in defaults apply.
+func newRetryPolicy(spec initializationv1alpha1.InitTargetSpec, defaultInitialDelay, defaultMaxDelay time.Duration) retryPolicy {
+	policy := retryPolicy{
+		initialDelay:  cmp.Or(defaultInitialDelay, defaultInitialRetryDelay),
+		maxDelay:      cmp.Or(defaultMaxDelay, defaultMaxRetryDelay),
+		failurePolicy: spec.FailurePolicy,
+	}
+
+	if policy.failurePolicy == "" {
+		policy.failurePolicy = initializationv1alpha1.FailurePolicyBlock
+	}
+
+	if rp := spec.RetryPolicy; rp != nil {
+		if rp.InitialDelay != nil && rp.InitialDelay.Duration > 0 {
+			policy.initialDelay = rp.InitialDelay.Duration
+		}
+		if rp.MaxDelay != nil && rp.MaxDelay.Duration > 0 {
+			policy.maxDelay = rp.MaxDelay.Duration
+		}
+		if rp.MaxAttempts != nil {
+			policy.maxAttempts = int(*rp.MaxAttempts)
+		}
+		if rp.Deadline != nil {
+			policy.deadline = rp.Deadline.Duration
+		}
+	}
+
+	policy.maxDelay = max(policy.maxDelay, policy.initialDelay)
+
+	return policy
+}
+
+// delay returns the time to wait after the given number of failed attempts.
+func (p retryPolicy) delay(attempts int) time.Duration {
+	delay := p.initialDelay
+	for i := 1; i < attempts && delay < p.maxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.maxDelay)
+}
+
+// deadlineExceeded returns true if the cluster has existed for longer than
+// the configured deadline.
+func (p retryPolicy) deadlineExceeded(lc *kcpcorev1alpha1.LogicalCluster, now time.Time) bool {
+	return p.deadline > 0 && now.Sub(lc.CreationTimestamp.Time) >= p.deadline
+}
+
+// untilDeadline returns the remaining time until the deadline is reached,
+// or 0 if no deadline is configured.
+func (p retryPolicy) untilDeadline(lc *kcpcorev1alpha1.LogicalCluster, now time.Time) time.Duration {
+	if p.deadline <= 0 {
+		return 0
+	}
+
+	return max(lc.CreationTimestamp.Add(p.deadline).Sub(now), 0)
+}
+
+// exhausted returns true if no more attempts should be made.
+func (p retryPolicy) exhausted(lc *kcpcorev1alpha1.LogicalCluster, attempts int, now time.Time) bool {
+	return (p.maxAttempts > 0 && attempts >= p.maxAttempts) || p.deadlineExceeded(lc, now)
+}
+
+func getAttempts(lc *kcpcorev1alpha1.LogicalCluster) map[string]int {
+	attempts := map[string]int{}
+
+	// Ignore malformed annotations, the worst case is that we start counting anew.
+	if value := lc.GetAnnotations()[initializationv1alpha1.AttemptsAnnotation]; value != "" {
+		_ = json.Unmarshal([]byte(value), &attempts)
+	}
+
+	return attempts
+}
+
+// setAttempts stores the number of failed attempts for the given initializer
+// on the LogicalCluster, so that it survives restarts of the agent. A count of
+// 0 removes the initializer from the annotation.
+func setAttempts(ctx context.Context, client ctrlruntimeclient.Client, lc *kcpcorev1alpha1.LogicalCluster, initializer kcpcorev1alpha1.LogicalClusterInitializer, count int) error {
+	attempts := getAttempts(lc)
+	if attempts[string(initializer)] == count {
+		return nil
+	}
+
+	if count > 0 {
+		attempts[string(initializer)] = count
+	} else {
+		delete(attempts, string(initializer))
+	}
+
+	oldCluster := lc.DeepCopy()
+	annotations := lc.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	if len(attempts) == 0 {
+		delete(annotations, initializationv1alpha1.AttemptsAnnotation)
+	} else {
+		encoded, err := json.Marshal(attempts)
+		if err != nil {
+			return fmt.Errorf("failed to encode attempts: %w", err)
+		}
+		annotations[initializationv1alpha1.AttemptsAnnotation] = string(encoded)
+	}
+
+	lc.SetAnnotations(annotations)
+
+	return patchMetadata(ctx, client, oldCluster, lc)
+}
+
+func hasListAnnotation(lc *kcpcorev1alpha1.LogicalCluster, annotation string, initializer kcpcorev1alpha1.LogicalClusterInitializer) bool {
+	return slices.Contains(listAnnotation(lc, annotation), string(initializer))
+}
+
+func listAnnotation(lc *kcpcorev1alpha1.LogicalCluster, annotation string) []string {
+	value := lc.GetAnnotations()[annotation]
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+// addToListAnnotation adds the initializer to a comma-separated annotation on
+// the LogicalCluster.
+func addToListAnnotation(ctx context.Context, client ctrlruntimeclient.Client, lc *kcpcorev1alpha1.LogicalCluster, annotation string, initializer kcpcorev1alpha1.LogicalClusterInitializer) error {
+	values := listAnnotation(lc, annotation)
+	if slices.Contains(values, string(initializer)) {
+		return nil
+	}
+
+	oldCluster := lc.DeepCopy()
+	annotations := lc.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	annotations[annotation] = strings.Join(append(values, string(initializer)), ",")
+	lc.SetAnnotations(annotations)
+
+	return patchMetadata(ctx, client, oldCluster, lc)
+}
+
+func patchMetadata(ctx context.Context, client ctrlruntimeclient.Client, oldCluster, lc *kcpcorev1alpha1.LogicalCluster) error {
+	// Use optimistic locking, as other initializers might be tracking their
+	// attempts on the same LogicalCluster.
+	patch := ctrlruntimeclient.MergeFromWithOptions(oldCluster, ctrlruntimeclient.MergeFromWithOptimisticLock{})
+	if err := client.Patch(ctx, lc, patch); err != nil {
+		return fmt.Errorf("failed to update LogicalCluster annotations: %w", err)
+	}
+
+	return nil
+}
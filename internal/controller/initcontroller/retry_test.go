@@ -0,0 +1,235 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+
+	"github.com/kcp-dev/init-agent/internal/initialize"
+	"github.com/kcp-dev/init-agent/internal/metrics"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	policy := newRetryPolicy(initializationv1alpha1.InitTargetSpec{
+		RetryPolicy: &initializationv1alpha1.RetryPolicy{
+			InitialDelay: &metav1.Duration{Duration: time.Second},
+			MaxDelay:     &metav1.Duration{Duration: 10 * time.Second},
+			MaxAttempts:  ptr.To[int32](5),
+			Deadline:     &metav1.Duration{Duration: time.Hour},
+		},
+	}, 0, 0)
+
+	if policy.failurePolicy != initializationv1alpha1.FailurePolicyBlock {
+		t.Errorf("Expected failure policy to default to Block, got %q.", policy.failurePolicy)
+	}
+
+	expectedDelays := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, expected := range expectedDelays {
+		if delay := policy.delay(i + 1); delay != expected {
+			t.Errorf("Expected delay after %d attempts to be %v, got %v.", i+1, expected, delay)
+		}
+	}
+
+	now := time.Now()
+	lc := &kcpcorev1alpha1.LogicalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.NewTime(now.Add(-30 * time.Minute)),
+		},
+	}
+
+	if policy.exhausted(lc, 4, now) {
+		t.Error("Expected policy not to be exhausted after 4 attempts.")
+	}
+
+	if !policy.exhausted(lc, 5, now) {
+		t.Error("Expected policy to be exhausted after 5 attempts.")
+	}
+
+	if remaining := policy.untilDeadline(lc, now); remaining != 30*time.Minute {
+		t.Errorf("Expected 30m until deadline, got %v.", remaining)
+	}
+
+	if !policy.exhausted(lc, 1, now.Add(time.Hour)) {
+		t.Error("Expected policy to be exhausted after the deadline.")
+	}
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	policy := newRetryPolicy(initializationv1alpha1.InitTargetSpec{}, 0, 0)
+	if policy.initialDelay != defaultInitialRetryDelay || policy.maxDelay != defaultMaxRetryDelay {
+		t.Errorf("Expected built-in defaults, got %v and %v.", policy.initialDelay, policy.maxDelay)
+	}
+
+	policy = newRetryPolicy(initializationv1alpha1.InitTargetSpec{}, time.Second, time.Minute)
+	if policy.initialDelay != time.Second || policy.maxDelay != time.Minute {
+		t.Errorf("Expected agent defaults, got %v and %v.", policy.initialDelay, policy.maxDelay)
+	}
+
+	policy = newRetryPolicy(initializationv1alpha1.InitTargetSpec{
+		RetryPolicy: &initializationv1alpha1.RetryPolicy{
+			InitialDelay: &metav1.Duration{Duration: 2 * time.Second},
+		},
+	}, time.Second, time.Minute)
+	if policy.initialDelay != 2*time.Second || policy.maxDelay != time.Minute {
+		t.Errorf("Expected InitTarget to override the agent defaults, got %v and %v.", policy.initialDelay, policy.maxDelay)
+	}
+}
+
+func TestAttemptsAnnotation(t *testing.T) {
+	lc := &kcpcorev1alpha1.LogicalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				initializationv1alpha1.AttemptsAnnotation: `{"root:a":3,"root:b":1}`,
+			},
+		},
+	}
+
+	attempts := getAttempts(lc)
+	if attempts["root:a"] != 3 || attempts["root:b"] != 1 {
+		t.Errorf("Unexpected attempts: %v", attempts)
+	}
+
+	lc.Annotations[initializationv1alpha1.AttemptsAnnotation] = "not json"
+	if attempts := getAttempts(lc); len(attempts) != 0 {
+		t.Errorf("Expected malformed annotation to be ignored, got %v.", attempts)
+	}
+}
+
+func TestFailureIsNotRetriedBeforeDelay(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kcpcorev1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed to build scheme: %v", err)
+	}
+
+	lc := &kcpcorev1alpha1.LogicalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "cluster",
+			Generation: 1,
+		},
+		Status: kcpcorev1alpha1.LogicalClusterStatus{
+			Initializers: []kcpcorev1alpha1.LogicalClusterInitializer{"root:a"},
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lc).Build()
+
+	current := &kcpcorev1alpha1.LogicalCluster{}
+	if err := client.Get(t.Context(), ctrlruntimeclient.ObjectKeyFromObject(lc), current); err != nil {
+		t.Fatalf("Failed to get LogicalCluster: %v", err)
+	}
+	before := current.DeepCopy()
+
+	r := &Reconciler{initializer: "root:a"}
+	target := &initializationv1alpha1.InitTarget{ObjectMeta: metav1.ObjectMeta{Name: "target"}}
+	policy := newRetryPolicy(target.Spec, time.Minute, time.Hour)
+
+	result, err := r.handleFailure(t.Context(), zap.NewNop().Sugar(), client, record.NewFakeRecorder(10), current, target, policy, errors.New("boom"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.RequeueAfter != time.Minute {
+		t.Errorf("Expected retry after %v, got %v.", time.Minute, result.RequeueAfter)
+	}
+
+	if attempts := getAttempts(current)["root:a"]; attempts != 1 {
+		t.Fatalf("Expected 1 recorded attempt, got %d.", attempts)
+	}
+
+	// Recording the attempt must not trigger an immediate reconciliation.
+	if initializationChanged().Update(event.UpdateEvent{ObjectOld: before, ObjectNew: current}) {
+		t.Error("Expected recording the attempt not to requeue the cluster.")
+	}
+
+	// Retrying a failed initialization is still relevant.
+	retried := current.DeepCopy()
+	current.Annotations[initializationv1alpha1.FailedAnnotation] = "root:a"
+	if !initializationChanged().Update(event.UpdateEvent{ObjectOld: current, ObjectNew: retried}) {
+		t.Error("Expected a changed failed annotation to requeue the cluster.")
+	}
+	delete(current.Annotations, initializationv1alpha1.FailedAnnotation)
+
+	// Removing an initializer is still relevant.
+	updated := current.DeepCopy()
+	updated.Status.Initializers = nil
+	if !initializationChanged().Update(event.UpdateEvent{ObjectOld: current, ObjectNew: updated}) {
+		t.Error("Expected a changed list of initializers to requeue the cluster.")
+	}
+}
+
+func TestExhaustedWorkspaceIsCountedOnce(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kcpcorev1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed to build scheme: %v", err)
+	}
+
+	lc := &kcpcorev1alpha1.LogicalCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lc).Build()
+
+	r := &Reconciler{initializer: "root:a", exhausted: newClusterSet(), opts: Options{InitTarget: "root:config/counted-once"}}
+	target := &initializationv1alpha1.InitTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "counted-once"},
+		Spec: initializationv1alpha1.InitTargetSpec{
+			RetryPolicy: &initializationv1alpha1.RetryPolicy{MaxAttempts: ptr.To[int32](1)},
+		},
+	}
+	policy := newRetryPolicy(target.Spec, 0, 0)
+	ctx := initialize.WithClusterName(t.Context(), "cluster")
+	recorder := record.NewFakeRecorder(10)
+
+	for range 3 {
+		current := &kcpcorev1alpha1.LogicalCluster{}
+		if err := client.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(lc), current); err != nil {
+			t.Fatalf("Failed to get LogicalCluster: %v", err)
+		}
+
+		if _, err := r.handleFailure(ctx, zap.NewNop().Sugar(), client, recorder, current, target, policy, errors.New("boom")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if attempts := testutil.ToFloat64(metrics.FailedAttempts.WithLabelValues(r.opts.InitTarget)); attempts != 3 {
+		t.Errorf("Expected 3 failed attempts, got %v.", attempts)
+	}
+
+	if failures := testutil.ToFloat64(metrics.InitializationFailures.WithLabelValues(r.opts.InitTarget)); failures != 1 {
+		t.Errorf("Expected the workspace to be counted as failed once, got %v.", failures)
+	}
+
+	if events := len(recorder.Events); events != 1 {
+		t.Errorf("Expected a single RetriesExhausted event, got %d.", events)
+	}
+}
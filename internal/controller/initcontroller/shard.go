@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcontroller
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
+)
+
+// ShardFilter decides which clusters are processed by this replica of the
+// init-agent.
+type ShardFilter interface {
+	// Owns returns true if this replica is responsible for the given cluster.
+	Owns(clusterName string) bool
+	// Subscribe returns a channel that is notified whenever the assignment of
+	// clusters to replicas changes.
+	Subscribe() (<-chan struct{}, func())
+}
+
+// shardTracker remembers all clusters that still need to be initialized,
+// regardless of whether they are owned by this replica or not, so that they
+// can be requeued when the shards are rebalanced.
+type shardTracker struct {
+	filter ShardFilter
+
+	lock     sync.Mutex
+	clusters map[string]struct{}
+}
+
+func newShardTracker(filter ShardFilter) *shardTracker {
+	return &shardTracker{
+		filter:   filter,
+		clusters: map[string]struct{}{},
+	}
+}
+
+// Owns records the cluster and returns whether it is owned by this replica.
+func (t *shardTracker) Owns(clusterName string) bool {
+	if t.filter == nil {
+		return true
+	}
+
+	t.lock.Lock()
+	t.clusters[clusterName] = struct{}{}
+	t.lock.Unlock()
+
+	return t.filter.Owns(clusterName)
+}
+
+func (t *shardTracker) Forget(clusterName string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.clusters, clusterName)
+}
+
+// Source returns a source that requeues all known clusters whenever the
+// shards are rebalanced.
+func (t *shardTracker) Source() source.TypedSource[mcreconcile.Request] {
+	return source.TypedFunc[mcreconcile.Request](func(ctx context.Context, queue workqueue.TypedRateLimitingInterface[mcreconcile.Request]) error {
+		if t.filter == nil {
+			return nil
+		}
+
+		changes, unsubscribe := t.filter.Subscribe()
+
+		go func() {
+			defer unsubscribe()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-changes:
+					t.lock.Lock()
+					for clusterName := range t.clusters {
+						queue.Add(mcreconcile.Request{
+							ClusterName: clusterName,
+							Request: reconcile.Request{
+								NamespacedName: types.NamespacedName{Name: "cluster"},
+							},
+						})
+					}
+					t.lock.Unlock()
+				}
+			}
+		}()
+
+		return nil
+	})
+}
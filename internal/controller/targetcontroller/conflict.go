@@ -0,0 +1,98 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetcontroller
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// conflict describes an init controller that was not started because another
+// InitTarget already handles the same initializer.
+type conflict struct {
+	loser  targetRef
+	winner targetRef
+}
+
+// checkConflict makes sure that only a single init controller is running per
+// initializer. If another InitTarget already handles the same initializer,
+// the older of both wins (ties are broken by workspace and name). If the given
+// target lost, the winning InitTarget is returned and the second return value
+// is true. Losing controllers of other InitTargets are stopped.
+func (r *Reconciler) checkConflict(log *zap.SugaredLogger, ref targetRef, target *initializationv1alpha1.InitTarget, wst resolvedWorkspaceType) (targetRef, bool) {
+	key := getControllerKey(target, wst.ref)
+
+	r.ctrlLock.Lock()
+	defer r.ctrlLock.Unlock()
+
+	for otherKey, other := range r.ctrlCancels {
+		// WorkspaceTypes are deduplicated per InitTarget already
+		if other.targetUID == target.UID || other.initializer != wst.initializer {
+			continue
+		}
+
+		if isOlder(other.created, other.target, target.CreationTimestamp, ref) {
+			r.conflicts[key] = conflict{loser: ref, winner: other.target}
+			return other.target, true
+		}
+
+		// this target takes precedence over the one that is currently running
+		log.Infow("Stopping init controller…", "ctrlkey", otherKey, "reason", "conflicting InitTarget", "winner", ref)
+		r.stopControllerLocked(otherKey, fmt.Sprintf("InitTarget %s handles the same initializer", ref))
+		r.conflicts[otherKey] = conflict{loser: other.target, winner: ref}
+		r.requeue(other.target)
+	}
+
+	delete(r.conflicts, key)
+
+	return targetRef{}, false
+}
+
+// requeueConflictsLocked requeues all InitTargets that lost against the given
+// InitTarget, so they can take over once it is gone. The caller must hold the
+// ctrlLock.
+func (r *Reconciler) requeueConflictsLocked(winner targetRef) {
+	for _, c := range r.conflicts {
+		if c.winner == winner {
+			r.requeue(c.loser)
+		}
+	}
+}
+
+// forgetConflictsLocked removes all conflicts of the given losing InitTarget.
+// The caller must hold the ctrlLock.
+func (r *Reconciler) forgetConflictsLocked(loser targetRef) {
+	for key, c := range r.conflicts {
+		if c.loser == loser {
+			delete(r.conflicts, key)
+		}
+	}
+}
+
+// isOlder returns true if the first InitTarget was created before the second.
+func isOlder(aCreated metav1.Time, a targetRef, bCreated metav1.Time, b targetRef) bool {
+	if !aCreated.Equal(&bCreated) {
+		return aCreated.Before(&bCreated)
+	}
+
+	return a.String() < b.String()
+}
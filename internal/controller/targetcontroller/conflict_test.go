@@ -0,0 +1,210 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetcontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const testWorkspace = "root:config"
+
+func newTestTarget(name string, created time.Time) *initializationv1alpha1.InitTarget {
+	return &initializationv1alpha1.InitTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			UID:               types.UID(name + "-uid"),
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+}
+
+func testRef(target *initializationv1alpha1.InitTarget) targetRef {
+	return targetRef{workspace: testWorkspace, name: target.Name}
+}
+
+func newTestReconciler() *Reconciler {
+	return &Reconciler{
+		ctrlCancels:  map[string]*initController{},
+		conflicts:    map[string]conflict{},
+		handled:      map[targetRef]struct{}{},
+		stateChanges: make(chan targetRef, 10),
+	}
+}
+
+func newTestWorkspaceType(name string, initializer string) resolvedWorkspaceType {
+	return resolvedWorkspaceType{
+		ref:         initializationv1alpha1.WorkspaceTypeReference{Path: "root", Name: name},
+		initializer: kcpcorev1alpha1.LogicalClusterInitializer(initializer),
+	}
+}
+
+func addTestController(r *Reconciler, ref targetRef, target *initializationv1alpha1.InitTarget, wst resolvedWorkspaceType) string {
+	_, cancel := context.WithCancelCause(context.Background())
+
+	key := getControllerKey(target, wst.ref)
+	r.ctrlCancels[key] = &initController{
+		target:        ref,
+		targetUID:     target.UID,
+		workspaceType: wst.ref,
+		initializer:   wst.initializer,
+		created:       target.CreationTimestamp,
+		cancel:        cancel,
+	}
+
+	return key
+}
+
+func TestCheckConflict(t *testing.T) {
+	log := zap.NewNop().Sugar()
+	now := time.Now()
+
+	older := newTestTarget("older", now.Add(-time.Hour))
+	younger := newTestTarget("younger", now)
+	unrelated := newTestTarget("unrelated", now.Add(-2*time.Hour))
+
+	wst := newTestWorkspaceType("type", "abc:type")
+
+	t.Run("younger target loses", func(t *testing.T) {
+		r := newTestReconciler()
+		olderKey := addTestController(r, testRef(older), older, wst)
+		addTestController(r, testRef(unrelated), unrelated, newTestWorkspaceType("other", "abc:other"))
+
+		if winner, lost := r.checkConflict(log, testRef(younger), younger, wst); !lost || winner != testRef(older) {
+			t.Fatalf("Expected %q to win, but got %q.", testRef(older), winner)
+		}
+
+		youngerKey := getControllerKey(younger, wst.ref)
+		if c := r.conflicts[youngerKey]; c.winner != testRef(older) {
+			t.Fatalf("Expected conflict to be recorded, got %v.", r.conflicts)
+		}
+
+		// once the winner is gone, the loser must be requeued
+		r.ctrlLock.Lock()
+		r.stopControllerLocked(olderKey, "test")
+		r.ctrlLock.Unlock()
+
+		select {
+		case ref := <-r.stateChanges:
+			if ref != testRef(younger) {
+				t.Fatalf("Expected %q to be requeued, but got %q.", testRef(younger), ref)
+			}
+		default:
+			t.Fatal("Expected losing InitTarget to be requeued.")
+		}
+
+		if winner, lost := r.checkConflict(log, testRef(younger), younger, wst); lost {
+			t.Fatalf("Expected no conflict anymore, but %q won.", winner)
+		}
+
+		if _, ok := r.conflicts[youngerKey]; ok {
+			t.Fatal("Expected conflict to be forgotten.")
+		}
+	})
+
+	t.Run("older target replaces running controller", func(t *testing.T) {
+		r := newTestReconciler()
+		youngerKey := addTestController(r, testRef(younger), younger, wst)
+
+		if winner, lost := r.checkConflict(log, testRef(older), older, wst); lost {
+			t.Fatalf("Expected %q to win, but %q won.", testRef(older), winner)
+		}
+
+		if _, exists := r.ctrlCancels[youngerKey]; exists {
+			t.Fatal("Expected controller of the younger InitTarget to be stopped.")
+		}
+
+		if c := r.conflicts[youngerKey]; c.winner != testRef(older) {
+			t.Fatalf("Expected conflict to be recorded, got %v.", r.conflicts)
+		}
+	})
+
+	t.Run("name breaks ties", func(t *testing.T) {
+		r := newTestReconciler()
+		a := newTestTarget("a", now)
+		b := newTestTarget("b", now)
+		addTestController(r, testRef(a), a, wst)
+
+		if winner, lost := r.checkConflict(log, testRef(b), b, wst); !lost || winner != testRef(a) {
+			t.Fatalf("Expected %q to win, but got %q.", testRef(a), winner)
+		}
+	})
+
+	t.Run("same name in different workspaces", func(t *testing.T) {
+		r := newTestReconciler()
+		first := newTestTarget("target", now)
+		second := newTestTarget("target", now)
+		second.UID = "other-uid"
+
+		firstRef := targetRef{workspace: "root:a", name: first.Name}
+		secondRef := targetRef{workspace: "root:b", name: second.Name}
+		firstKey := addTestController(r, firstRef, first, wst)
+
+		if winner, lost := r.checkConflict(log, secondRef, second, wst); !lost || winner != firstRef {
+			t.Fatalf("Expected %q to win, but got %q.", firstRef, winner)
+		}
+
+		// the controller of the other workspace's InitTarget must be left alone
+		r.stopControllersForTarget(log, secondRef)
+
+		if _, exists := r.ctrlCancels[firstKey]; !exists {
+			t.Fatal("Expected controller of the InitTarget in the other workspace to keep running.")
+		}
+	})
+
+	t.Run("same initializer via different path", func(t *testing.T) {
+		r := newTestReconciler()
+		addTestController(r, testRef(older), older, wst)
+
+		aliased := wst
+		aliased.ref.Path = "1234abcd"
+
+		if winner, lost := r.checkConflict(log, testRef(younger), younger, aliased); !lost || winner != testRef(older) {
+			t.Fatalf("Expected %q to win, but got %q.", testRef(older), winner)
+		}
+	})
+}
+
+func TestDedupeWorkspaceTypes(t *testing.T) {
+	wsts := dedupeWorkspaceTypes([]resolvedWorkspaceType{
+		newTestWorkspaceType("a", "abc:a"),
+		newTestWorkspaceType("a", "abc:a"),
+		{ref: initializationv1alpha1.WorkspaceTypeReference{Path: "root:alias", Name: "a"}, initializer: "abc:a"},
+		newTestWorkspaceType("b", "abc:b"),
+		newTestWorkspaceType("missing", ""),
+	})
+
+	if len(wsts) != 3 {
+		t.Fatalf("Expected 3 WorkspaceTypes, got %d: %v", len(wsts), wsts)
+	}
+
+	for i, name := range []string{"a", "b", "missing"} {
+		if wsts[i].ref.Name != name {
+			t.Errorf("Expected WorkspaceType %d to be %q, got %q.", i, name, wsts[i].ref.Name)
+		}
+	}
+}
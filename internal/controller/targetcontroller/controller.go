@@ -21,227 +21,561 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/kcp-dev/init-agent/internal/controller/initcontroller"
 	"github.com/kcp-dev/init-agent/internal/controllerutil/predicate"
+	"github.com/kcp-dev/init-agent/internal/initprovider"
 	"github.com/kcp-dev/init-agent/internal/kcp"
+	"github.com/kcp-dev/init-agent/internal/metrics"
+	"github.com/kcp-dev/init-agent/internal/settings"
 	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
 
 	"github.com/kcp-dev/logicalcluster/v3"
-	"github.com/kcp-dev/multicluster-provider/initializingworkspaces"
 	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
-	kcptenancyinitialization "github.com/kcp-dev/sdk/apis/tenancy/initialization"
 	kcptenancyv1alpha1 "github.com/kcp-dev/sdk/apis/tenancy/v1alpha1"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/builder"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	mcbuilder "sigs.k8s.io/multicluster-runtime/pkg/builder"
+	mccontroller "sigs.k8s.io/multicluster-runtime/pkg/controller"
 	mcmanager "sigs.k8s.io/multicluster-runtime/pkg/manager"
+	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
 )
 
 const (
 	ControllerName = "initagent-target-controller"
+
+	// CleanupFinalizer is placed on InitTargets to make sure the init controller
+	// is stopped before the InitTarget disappears.
+	CleanupFinalizer = "initialization.kcp.io/cleanup"
+
+	// ReadyzCheckName is the name of the readyz check that reports the state
+	// of all init controllers.
+	ReadyzCheckName = "init-controllers"
 )
 
-type NewInitControllerFunc func(remoteManager mcmanager.Manager, targetProvider initcontroller.InitTargetProvider, initializer kcpcorev1alpha1.LogicalClusterInitializer) error
+// NewInitControllerFunc creates the init controller for a single WorkspaceType
+// with the given number of workers.
+type NewInitControllerFunc func(remoteManager mcmanager.Manager, initTarget string, targetProvider initcontroller.InitTargetProvider, reporter initcontroller.ResultReporter, initializer kcpcorev1alpha1.LogicalClusterInitializer, workers int) error
 
 type Reconciler struct {
 	// Choose to break good practice of never storing a context in a struct,
-	// and instead opt to use the app's root context for the dynamically
-	// started clusters, so when the Init Agent shuts down, their shutdown is
-	// also triggered.
+	// and instead opt to use the context of the leader election runnable (see
+	// Start) for the dynamically started clusters, so when the Init Agent
+	// shuts down or loses its leadership, their shutdown is also triggered.
+	// This is nil until the runnable was started.
 	ctx context.Context
 
-	localClient       ctrlruntimeclient.Client
+	mgr               mcmanager.Manager
+	workspaces        *workspaceProvider
 	log               *zap.SugaredLogger
+	settings          *settings.Store
 	clusterClient     kcp.ClusterClient
+	providers         *initprovider.Pool
 	newInitController NewInitControllerFunc
 
-	// A map of cancel funcs for the multicluster managers
-	// that we launch for each InitTarget.
-	ctrlCancels map[string]context.CancelCauseFunc
+	// runManager runs the multicluster manager of an init controller until it
+	// fails or the context is cancelled. This is runMulticlusterManager, except
+	// in tests.
+	runManager func(ctx context.Context, log *zap.SugaredLogger, ctrl *initController) error
+
+	// A map of the multicluster managers that we launch for each WorkspaceType
+	// of each InitTarget, keyed by getControllerKey(). Must only be accessed
+	// while holding the ctrlLock.
+	ctrlCancels map[string]*initController
 	ctrlLock    sync.Mutex
+
+	// managers keeps track of all running multicluster managers, so that a
+	// leadership handover can wait for them to be stopped. Add must only be
+	// called while holding the ctrlLock and not after stopped has been set.
+	managers sync.WaitGroup
+
+	// stopped is set by Start once it waits for all managers to finish. Must
+	// only be accessed while holding the ctrlLock.
+	stopped bool
+
+	// stateChanges is used by the supervisors to requeue InitTargets whose
+	// init controller changed its state.
+	stateChanges chan targetRef
+
+	// conflicts keeps track of the controllers that were not started because
+	// another InitTarget handles the same initializer, keyed by the controller
+	// key. Must only be accessed while holding the ctrlLock.
+	conflicts map[string]conflict
+
+	// handled contains the InitTargets this agent has added its finalizer to
+	// since it was started. As all agents share the same finalizer, it is only
+	// removed from these when they stop matching the selector. Must only be
+	// accessed while holding the ctrlLock.
+	handled map[targetRef]struct{}
 }
 
-// Add creates a new controller and adds it to the given manager.
+// initController is a supervised multicluster manager for a single
+// WorkspaceType of an InitTarget. Its state fields must only be accessed while
+// holding the ctrlLock.
+type initController struct {
+	target        targetRef
+	targetUID     types.UID
+	workspaceType initializationv1alpha1.WorkspaceTypeReference
+	initializer   kcpcorev1alpha1.LogicalClusterInitializer
+	workers       int
+	created       metav1.Time
+	cancel        context.CancelCauseFunc
+
+	state      controllerState
+	lastError  error
+	restarts   int32
+	lastResult *initResult
+}
+
+// initResult is the outcome of the most recent initialization of a workspace
+// by an init controller.
+type initResult struct {
+	workspace logicalcluster.Path
+	err       error
+	time      time.Time
+}
+
+// targetRef identifies an InitTarget. InitTarget names are only unique within
+// their config workspace.
+type targetRef struct {
+	workspace string
+	name      string
+}
+
+func (t targetRef) String() string {
+	return t.workspace + "/" + t.name
+}
+
+func (t targetRef) request() mcreconcile.Request {
+	return mcreconcile.Request{
+		ClusterName: t.workspace,
+		Request: reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: t.name},
+		},
+	}
+}
+
+// Add creates a new controller and adds it to the given manager. The controller
+// processes the InitTargets in the manager's workspace and all additional
+// config workspaces. It only runs while the manager is the leader (if leader
+// election is enabled).
 func Add(
-	ctx context.Context,
-	mgr manager.Manager,
+	localMgr manager.Manager,
 	log *zap.SugaredLogger,
-	targetFilter labels.Selector,
+	settings *settings.Store,
 	clusterClient kcp.ClusterClient,
+	workspaces Workspaces,
 	newInitController NewInitControllerFunc,
 ) error {
+	providers, err := initprovider.NewPool(clusterClient, log)
+	if err != nil {
+		return fmt.Errorf("failed to create provider pool: %w", err)
+	}
+
+	workspaceProvider, err := newWorkspaceProvider(localMgr, clusterClient, workspaces)
+	if err != nil {
+		return fmt.Errorf("failed to create config workspace provider: %w", err)
+	}
+
+	mgr, err := mcmanager.WithMultiCluster(localMgr, workspaceProvider)
+	if err != nil {
+		return fmt.Errorf("failed to create multicluster manager: %w", err)
+	}
+
+	// engage the config workspaces once this agent is the leader
+	if err := localMgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return workspaceProvider.Start(ctx, mgr)
+	})); err != nil {
+		return fmt.Errorf("failed to add config workspace provider: %w", err)
+	}
+
 	reconciler := &Reconciler{
-		ctx:               ctx,
-		localClient:       mgr.GetClient(),
+		mgr:               mgr,
+		workspaces:        workspaceProvider,
 		log:               log,
+		settings:          settings,
 		clusterClient:     clusterClient,
+		providers:         providers,
 		newInitController: newInitController,
-		ctrlCancels:       map[string]context.CancelCauseFunc{},
+		ctrlCancels:       map[string]*initController{},
 		ctrlLock:          sync.Mutex{},
+		stateChanges:      make(chan targetRef, 100),
+		conflicts:         map[string]conflict{},
+		handled:           map[targetRef]struct{}{},
+	}
+	reconciler.runManager = reconciler.runMulticlusterManager
+
+	if err := localMgr.Add(reconciler); err != nil {
+		return fmt.Errorf("failed to add leader runnable: %w", err)
+	}
+
+	if err := localMgr.AddReadyzCheck(ReadyzCheckName, reconciler.readyzCheck); err != nil {
+		return fmt.Errorf("failed to add readyz check: %w", err)
 	}
 
-	return builder.
+	return mcbuilder.
 		ControllerManagedBy(mgr).
 		Named(ControllerName).
-		WithOptions(controller.Options{
+		WithOptions(mccontroller.Options{
 			MaxConcurrentReconciles: 1,
 		}).
-		For(&initializationv1alpha1.InitTarget{}, builder.WithPredicates(predicate.ByLabels(targetFilter))).
+		// The predicate lets through updates where either the old or the new object
+		// matches, so that relabeled InitTargets can be cleaned up.
+		For(&initializationv1alpha1.InitTarget{}, mcbuilder.WithPredicates(predicate.Factory(func(o ctrlruntimeclient.Object) bool {
+			return reconciler.targetFilter().Matches(labels.Set(o.GetLabels()))
+		}))).
+		WatchesRawSource(reconciler.stateChangesSource()).
+		WatchesRawSource(reconciler.settingsSource()).
 		Complete(reconciler)
 }
 
-func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+func (r *Reconciler) Reconcile(ctx context.Context, req mcreconcile.Request) (reconcile.Result, error) {
+	ref := targetRef{workspace: req.ClusterName, name: req.Name}
+
 	log := r.log.Named(ControllerName)
-	log.With("request", req.Name).Debug("Processing")
+	log.With("request", ref).Debug("Processing")
+
+	cluster, err := r.mgr.GetCluster(ctx, req.ClusterName)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to get config workspace: %w", err)
+	}
+	client := cluster.GetClient()
 
 	target := &initializationv1alpha1.InitTarget{}
-	if err := r.localClient.Get(ctx, req.NamespacedName, target); err != nil {
-		return reconcile.Result{}, ctrlruntimeclient.IgnoreNotFound(err)
+	if err := client.Get(ctx, req.NamespacedName, target); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The InitTarget is gone without us having seen its deletion (e.g. the
+			// finalizer was removed by another replica), so stop whatever is left.
+			r.stopControllersForTarget(log, ref)
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	if target.DeletionTimestamp != nil {
+		r.cleanupController(log, ref, target, "InitTarget was deleted")
+		return reconcile.Result{}, r.removeFinalizer(ctx, client, target)
+	}
+
+	// The InitTarget was relabeled and must not be processed by this agent
+	// anymore. InitTargets that were never handled by this agent might belong
+	// to other agents, so their finalizer is left alone.
+	if !r.targetFilter().Matches(labels.Set(target.Labels)) {
+		if !r.cleanupController(log, ref, target, "InitTarget does not match the selector anymore") {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, r.removeFinalizer(ctx, client, target)
+	}
+
+	if err := r.ensureFinalizer(ctx, client, target); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	r.markHandled(ref)
+
+	// the leader runnable has not been started yet or is already shutting down
+	if !r.isLeading() {
+		return reconcile.Result{RequeueAfter: time.Second}, nil
+	}
+
+	// tenants may only initialize workspaces of their own WorkspaceTypes, so
+	// foreign references are dropped before anything is looked up
+	tenant := r.workspaces.isTenant(req.ClusterName)
+	resolveTarget := target
+
+	var forbidden []string
+	if tenant {
+		resolveTarget, forbidden = restrictToTenant(req.ClusterName, target)
+	}
+
+	wsts, err := r.resolveWorkspaceTypes(ctx, log, resolveTarget)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// WorkspaceTypes that do not exist (yet) are not used either
+	var missing []string
+	if tenant {
+		wsts, missing = checkOwnership(req.ClusterName, wsts)
+		forbidden = append(forbidden, missing...)
+	}
+
+	if len(forbidden) > 0 {
+		log.Warnw("InitTarget refers to WorkspaceTypes outside of its workspace", "name", ref, "forbidden", forbidden)
 	}
 
+	// Only one init controller may handle each initializer, otherwise they would
+	// race against each other.
 	var (
-		err    error
-		result reconcile.Result
+		active    []resolvedWorkspaceType
+		conflicts []string
 	)
 
-	if target.DeletionTimestamp != nil {
-		err = r.cleanupController(log, target)
-	} else {
-		result, err = r.ensureInitController(ctx, log, target)
+	for _, wst := range wsts {
+		if wst.initializer != "" {
+			if winner, lost := r.checkConflict(log, ref, target, wst); lost {
+				log.Warnw("InitTarget conflicts with another InitTarget for the same initializer", "name", ref, "winner", winner, "initializer", wst.initializer)
+				conflicts = append(conflicts, fmt.Sprintf("InitTarget %s already handles the initializer %s.", winner, wst.initializer))
+				continue
+			}
+		}
+
+		active = append(active, wst)
 	}
 
-	return result, err
+	r.ensureInitControllers(log, ref, target, active)
+
+	result := reconcile.Result{}
+	if resolveTarget.Spec.WorkspaceTypeSelector != nil || len(missing) > 0 {
+		result.RequeueAfter = workspaceTypeResyncInterval
+	}
+
+	return result, r.updateStatus(ctx, client, ref, target, conflicts, forbidden)
 }
 
-func (r *Reconciler) ensureInitController(ctx context.Context, log *zap.SugaredLogger, target *initializationv1alpha1.InitTarget) (reconcile.Result, error) {
-	key := getInitTargetKey(target)
+// Start is called once this agent has become the leader. It keeps track of the
+// leadership context and, once leadership is lost (or the agent shuts down),
+// stops all multicluster managers and waits for them to finish, so that
+// another replica can cleanly take over.
+func (r *Reconciler) Start(ctx context.Context) error {
+	r.ctrlLock.Lock()
+	r.ctx = ctx
+	r.stopped = false
+	r.ctrlLock.Unlock()
+
+	<-ctx.Done()
+
+	r.log.Info("Stopping all init controllers…")
 
-	// controller already exists
-	if _, exists := r.ctrlCancels[key]; exists {
-		return reconcile.Result{}, nil
+	// No new managers must be started once we wait for the running ones.
+	r.ctrlLock.Lock()
+	r.stopped = true
+	for key := range r.ctrlCancels {
+		r.stopControllerLocked(key, "agent is shutting down or lost leadership")
 	}
+	r.ctrlLock.Unlock()
 
-	ctrlog := log.With("ctrlkey", key, "name", target.Name)
+	r.managers.Wait()
+	r.log.Info("All init controllers have been stopped")
 
-	// fetch the WorkspaceType associated with this InitTarget
-	wst, err := r.getWorkspaceType(ctx, target)
-	if err != nil {
-		return reconcile.Result{}, fmt.Errorf("failed to retrieve WorkspaceType: %w", err)
-	}
+	return nil
+}
 
-	initializer := kcptenancyinitialization.InitializerForType(wst)
-	ctrlog = ctrlog.With("initializer", initializer)
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (r *Reconciler) NeedLeaderElection() bool {
+	return true
+}
 
-	ctrlog.Info("Creating new init controller…")
+func (r *Reconciler) isLeading() bool {
+	r.ctrlLock.Lock()
+	defer r.ctrlLock.Unlock()
 
-	mgr, err := r.createMulticlusterManager(wst)
-	if err != nil {
-		return reconcile.Result{}, fmt.Errorf("failed to create multicluster manager: %w", err)
+	return r.isLeadingLocked()
+}
+
+// isLeadingLocked is like isLeading, but the caller must hold the ctrlLock.
+func (r *Reconciler) isLeadingLocked() bool {
+	return r.ctx != nil && r.ctx.Err() == nil && !r.stopped
+}
+
+// ensureInitControllers makes sure exactly one init controller is running for
+// each of the given WorkspaceTypes and stops the InitTarget's controllers for
+// all other WorkspaceTypes.
+func (r *Reconciler) ensureInitControllers(log *zap.SugaredLogger, ref targetRef, target *initializationv1alpha1.InitTarget, wsts []resolvedWorkspaceType) {
+	desired := map[string]resolvedWorkspaceType{}
+	for _, wst := range wsts {
+		desired[getControllerKey(target, wst.ref)] = wst
 	}
 
-	if err := r.newInitController(mgr, r.newInitTargetProvider(target.Name), initializer); err != nil {
-		return reconcile.Result{}, fmt.Errorf("failed to create init controller: %w", err)
+	workers := r.settings.Get().InitWorkers
+	if target.Spec.Workers != nil {
+		workers = int(*target.Spec.Workers)
 	}
 
-	// Use the global app context so this provider is independent of the reconcile
-	// context, which might get cancelled right after Reconcile() is done.
-	ctrlCtx, ctrlCancel := context.WithCancelCause(r.ctx)
+	r.ctrlLock.Lock()
+	defer r.ctrlLock.Unlock()
 
-	r.ctrlCancels[key] = ctrlCancel
+	// Leadership might have been lost since Reconcile checked it; Start is then
+	// (about to be) waiting for all managers and no new ones may be added.
+	if !r.isLeadingLocked() {
+		return
+	}
+
+	for key, ctrl := range r.ctrlCancels {
+		if ctrl.targetUID != target.UID {
+			continue
+		}
+
+		if _, ok := desired[key]; !ok {
+			log.Infow("Stopping init controller…", "ctrlkey", key, "reason", "WorkspaceType is not targeted anymore")
+			r.stopControllerLocked(key, "WorkspaceType is not targeted anymore")
+		}
+	}
 
-	// cleanup when the context is done
-	go func() {
-		<-ctrlCtx.Done()
+	for key, wst := range desired {
+		existing, exists := r.ctrlCancels[key]
+		if exists && existing.workers != workers {
+			// the number of workers can only be configured when creating a controller
+			log.Infow("Number of workers has changed, restarting init controller…", "ctrlkey", key)
+			r.stopControllerLocked(key, "number of workers has changed")
+			exists = false
+		}
 
-		r.ctrlLock.Lock()
-		defer r.ctrlLock.Unlock()
+		// controller already exists
+		if exists {
+			if wst.initializer != "" {
+				existing.initializer = wst.initializer
+			}
 
-		delete(r.ctrlCancels, key)
-	}()
+			continue
+		}
 
-	// time to start the manager
-	go func() {
-		if err = mgr.Start(ctrlCtx); err != nil && !errors.Is(err, context.Canceled) {
-			ctrlCancel(errors.New("failed to start sync controller"))
-			ctrlog.Errorw("Failed to run multicluster manager", zap.Error(err))
+		ctrlog := log.With("ctrlkey", key, "name", ref, "workspacetype", wst.ref.Name)
+
+		// Use the leader context so this provider is independent of the reconcile
+		// context, which might get cancelled right after Reconcile() is done.
+		ctrlCtx, ctrlCancel := context.WithCancelCause(r.ctx)
+
+		ctrl := &initController{
+			target:        ref,
+			targetUID:     target.UID,
+			workspaceType: wst.ref,
+			initializer:   wst.initializer,
+			workers:       workers,
+			created:       target.CreationTimestamp,
+			cancel:        ctrlCancel,
+			state:         stateStarting,
 		}
-	}()
 
-	return reconcile.Result{}, nil
+		r.ctrlCancels[key] = ctrl
+
+		// the supervisor creates, starts and restarts the manager
+		r.managers.Add(1)
+		go func() {
+			defer r.managers.Done()
+			r.supervise(ctrlCtx, ctrlog, ctrl)
+		}()
+	}
+
+	metrics.RunningManagers.Set(float64(len(r.ctrlCancels)))
+}
+
+// markHandled remembers that this agent has added its finalizer to the
+// InitTarget.
+func (r *Reconciler) markHandled(ref targetRef) {
+	r.ctrlLock.Lock()
+	defer r.ctrlLock.Unlock()
+
+	r.handled[ref] = struct{}{}
 }
 
-func (r *Reconciler) cleanupController(log *zap.SugaredLogger, target *initializationv1alpha1.InitTarget) error {
-	key := getInitTargetKey(target)
-	log.Infow("Stopping init controller…", "ctrlkey", key)
+// cleanupController stops all controllers of the InitTarget and returns
+// whether the InitTarget was handled by this agent.
+func (r *Reconciler) cleanupController(log *zap.SugaredLogger, ref targetRef, target *initializationv1alpha1.InitTarget, reason string) bool {
+	metrics.InitTargetSuspended.DeleteLabelValues(ref.String())
 
 	r.ctrlLock.Lock()
 	defer r.ctrlLock.Unlock()
 
-	cancel, ok := r.ctrlCancels[key]
-	if ok {
-		cancel(errors.New("controller is no longer needed"))
-		delete(r.ctrlCancels, key)
+	r.forgetConflictsLocked(ref)
+
+	_, handled := r.handled[ref]
+	delete(r.handled, ref)
+
+	for key, ctrl := range r.ctrlCancels {
+		if ctrl.targetUID == target.UID {
+			log.Infow("Stopping init controller…", "ctrlkey", key, "reason", reason)
+			r.stopControllerLocked(key, reason)
+		}
 	}
 
-	return nil
+	return handled
 }
 
-func (r *Reconciler) getWorkspaceType(ctx context.Context, target *initializationv1alpha1.InitTarget) (*kcptenancyv1alpha1.WorkspaceType, error) {
-	wstCluster := logicalcluster.Name(target.Spec.WorkspaceTypeReference.Path)
-	if wstCluster == "" {
-		wstCluster = kcp.ClusterNameFromObject(target)
-	}
+// stopControllersForTarget stops all controllers that were started for
+// the given InitTarget, regardless of its UID.
+func (r *Reconciler) stopControllersForTarget(log *zap.SugaredLogger, ref targetRef) {
+	metrics.InitTargetSuspended.DeleteLabelValues(ref.String())
 
-	scheme := runtime.NewScheme()
+	r.ctrlLock.Lock()
+	defer r.ctrlLock.Unlock()
 
-	if err := kcptenancyv1alpha1.AddToScheme(scheme); err != nil {
-		return nil, fmt.Errorf("failed to register local scheme %s: %w", kcptenancyv1alpha1.SchemeGroupVersion, err)
-	}
+	r.forgetConflictsLocked(ref)
+	delete(r.handled, ref)
 
-	wstClient, err := r.clusterClient.Cluster(wstCluster, scheme)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create client for WorkspaceType cluster: %w", err)
+	for key, ctrl := range r.ctrlCancels {
+		if ctrl.target == ref {
+			log.Infow("Stopping init controller…", "ctrlkey", key, "reason", "InitTarget does not exist anymore")
+			r.stopControllerLocked(key, "InitTarget does not exist anymore")
+		}
 	}
+}
 
-	wst := &kcptenancyv1alpha1.WorkspaceType{}
-	if err := wstClient.Get(ctx, types.NamespacedName{Name: target.Spec.WorkspaceTypeReference.Name}, wst); err != nil {
-		return nil, err
+// stopControllerLocked stops the controller with the given key. The caller
+// must hold the ctrlLock.
+func (r *Reconciler) stopControllerLocked(key string, reason string) {
+	ctrl, ok := r.ctrlCancels[key]
+	if !ok {
+		return
 	}
 
-	return wst, nil
+	ctrl.cancel(errors.New(reason))
+	delete(r.ctrlCancels, key)
+	metrics.RunningManagers.Set(float64(len(r.ctrlCancels)))
+
+	// InitTargets that lost against this one might be able to start now
+	r.requeueConflictsLocked(ctrl.target)
 }
 
-func (r *Reconciler) createMulticlusterManager(wst *kcptenancyv1alpha1.WorkspaceType) (mcmanager.Manager, error) {
-	wstConfig := r.clusterClient.ClusterConfig(kcp.ClusterNameFromObject(wst))
+func (r *Reconciler) ensureFinalizer(ctx context.Context, client ctrlruntimeclient.Client, target *initializationv1alpha1.InitTarget) error {
+	if controllerutil.ContainsFinalizer(target, CleanupFinalizer) {
+		return nil
+	}
 
-	scheme := runtime.NewScheme()
+	oldTarget := target.DeepCopy()
+	controllerutil.AddFinalizer(target, CleanupFinalizer)
 
-	if err := kcptenancyv1alpha1.AddToScheme(scheme); err != nil {
-		return nil, fmt.Errorf("failed to register local scheme %s: %w", kcptenancyv1alpha1.SchemeGroupVersion, err)
+	if err := client.Patch(ctx, target, ctrlruntimeclient.MergeFromWithOptions(oldTarget, ctrlruntimeclient.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("failed to add finalizer: %w", err)
 	}
 
-	if err := kcpcorev1alpha1.AddToScheme(scheme); err != nil {
-		return nil, fmt.Errorf("failed to register local scheme %s: %w", kcpcorev1alpha1.SchemeGroupVersion, err)
+	return nil
+}
+
+func (r *Reconciler) removeFinalizer(ctx context.Context, client ctrlruntimeclient.Client, target *initializationv1alpha1.InitTarget) error {
+	if !controllerutil.ContainsFinalizer(target, CleanupFinalizer) {
+		return nil
 	}
 
-	provider, err := initializingworkspaces.New(wstConfig, wst.Name, initializingworkspaces.Options{
-		Scheme: scheme,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create provider: %w", err)
+	oldTarget := target.DeepCopy()
+	controllerutil.RemoveFinalizer(target, CleanupFinalizer)
+
+	if err := client.Patch(ctx, target, ctrlruntimeclient.MergeFromWithOptions(oldTarget, ctrlruntimeclient.MergeFromWithOptimisticLock{})); err != nil {
+		return ctrlruntimeclient.IgnoreNotFound(fmt.Errorf("failed to remove finalizer: %w", err))
 	}
 
-	mgr, err := mcmanager.New(wstConfig, provider, manager.Options{
-		Scheme: scheme,
+	return nil
+}
+
+func (r *Reconciler) createMulticlusterManager(wst *kcptenancyv1alpha1.WorkspaceType) (mcmanager.Manager, error) {
+	wstConfig := r.clusterClient.ClusterConfig(kcp.ClusterNameFromObject(wst))
+
+	// the provider shares its WorkspaceType informer with all other providers
+	// for WorkspaceTypes in the same workspace
+	mgr, err := mcmanager.New(wstConfig, r.providers.NewProvider(wst), manager.Options{
+		Scheme: r.providers.Scheme(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create manager: %w", err)
@@ -250,17 +584,28 @@ func (r *Reconciler) createMulticlusterManager(wst *kcptenancyv1alpha1.Workspace
 	return mgr, nil
 }
 
-func (r *Reconciler) newInitTargetProvider(name string) initcontroller.InitTargetProvider {
+func (r *Reconciler) newInitTargetProvider(ref targetRef) initcontroller.InitTargetProvider {
 	return func(ctx context.Context) (*initializationv1alpha1.InitTarget, error) {
+		cluster, err := r.mgr.GetCluster(ctx, ref.workspace)
+		if err != nil {
+			return nil, err
+		}
+
 		target := &initializationv1alpha1.InitTarget{}
-		if err := r.localClient.Get(ctx, types.NamespacedName{Name: name}, target); err != nil {
+		if err := cluster.GetClient().Get(ctx, types.NamespacedName{Name: ref.name}, target); err != nil {
 			return nil, err
 		}
 
+		if r.workspaces.isTenant(ref.workspace) {
+			capTenantPriority(target)
+		}
+
 		return target, nil
 	}
 }
 
-func getInitTargetKey(target *initializationv1alpha1.InitTarget) string {
-	return string(target.UID)
+// getControllerKey returns the key of the init controller for the given
+// WorkspaceType of an InitTarget.
+func getControllerKey(target *initializationv1alpha1.InitTarget, ref initializationv1alpha1.WorkspaceTypeReference) string {
+	return fmt.Sprintf("%s/%s/%s", target.UID, ref.Path, ref.Name)
 }
@@ -0,0 +1,690 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetcontroller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+
+	"github.com/kcp-dev/init-agent/internal/metrics"
+	"github.com/kcp-dev/init-agent/internal/settings"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+	kcptenancyv1alpha1 "github.com/kcp-dev/sdk/apis/tenancy/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	mcmanager "sigs.k8s.io/multicluster-runtime/pkg/manager"
+	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
+)
+
+// fakeManager serves the config workspaces from fake clients.
+type fakeManager struct {
+	mcmanager.Manager
+	clusters map[string]cluster.Cluster
+}
+
+func (m *fakeManager) GetCluster(_ context.Context, name string) (cluster.Cluster, error) {
+	cl, ok := m.clusters[name]
+	if !ok {
+		return nil, errors.New("cluster not found")
+	}
+
+	return cl, nil
+}
+
+type fakeCluster struct {
+	cluster.Cluster
+	client ctrlruntimeclient.Client
+}
+
+func (c *fakeCluster) GetClient() ctrlruntimeclient.Client {
+	return c.client
+}
+
+func (c *fakeCluster) GetCache() cache.Cache {
+	return &fakeCache{reader: c.client}
+}
+
+type fakeCache struct {
+	cache.Cache
+	reader ctrlruntimeclient.Reader
+}
+
+func (c *fakeCache) List(ctx context.Context, list ctrlruntimeclient.ObjectList, opts ...ctrlruntimeclient.ListOption) error {
+	return c.reader.List(ctx, list, opts...)
+}
+
+// newFakeConfigWorkspace returns a reconciler whose only config workspace
+// (testWorkspace) contains the given objects.
+func newFakeConfigWorkspace(t *testing.T, r *Reconciler, objects ...ctrlruntimeclient.Object) ctrlruntimeclient.Client {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := initializationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed to register scheme: %v", err)
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(&initializationv1alpha1.InitTarget{}).
+		Build()
+
+	r.mgr = &fakeManager{clusters: map[string]cluster.Cluster{
+		testWorkspace: &fakeCluster{client: client},
+	}}
+	r.workspaces = &workspaceProvider{
+		primary:       testWorkspace,
+		activeTenants: map[string]struct{}{},
+	}
+
+	return client
+}
+
+// fakeClusterClient serves WorkspaceTypes from fake clients.
+type fakeClusterClient struct {
+	clients map[logicalcluster.Name]ctrlruntimeclient.Client
+}
+
+func (c *fakeClusterClient) Cluster(name logicalcluster.Name, _ *runtime.Scheme) (ctrlruntimeclient.Client, error) {
+	client, ok := c.clients[name]
+	if !ok {
+		return nil, errors.New("cluster not found")
+	}
+
+	return client, nil
+}
+
+func (c *fakeClusterClient) ClusterConfig(logicalcluster.Name) *rest.Config {
+	return &rest.Config{}
+}
+
+// newFakeWorkspaceTypes makes the given WorkspaceTypes available in the
+// workspace "root".
+func newFakeWorkspaceTypes(t *testing.T, r *Reconciler, names ...string) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := kcptenancyv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed to register scheme: %v", err)
+	}
+
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, name := range names {
+		builder.WithObjects(&kcptenancyv1alpha1.WorkspaceType{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{logicalcluster.AnnotationKey: "root"},
+			},
+			Spec: kcptenancyv1alpha1.WorkspaceTypeSpec{Initializer: true},
+		})
+	}
+
+	r.clusterClient = &fakeClusterClient{clients: map[logicalcluster.Name]ctrlruntimeclient.Client{
+		"root": builder.Build(),
+	}}
+}
+
+// fakeManagers keeps track of the managers that are running.
+type fakeManagers struct {
+	lock    sync.Mutex
+	running map[*initController]bool
+}
+
+func (m *fakeManagers) set(ctrl *initController, running bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.running[ctrl] = running
+}
+
+// waitForStop waits until the manager of the given controller has stopped.
+func (m *fakeManagers) waitForStop(t *testing.T, ctrl *initController) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.lock.Lock()
+		running := m.running[ctrl]
+		m.lock.Unlock()
+
+		if !running {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the manager to be stopped.")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// startLeading makes the reconciler start init controllers, whose managers
+// run until they are stopped. All of them are stopped when the test ends.
+func startLeading(t *testing.T, r *Reconciler) *fakeManagers {
+	t.Helper()
+
+	managers := &fakeManagers{running: map[*initController]bool{}}
+
+	r.runManager = func(ctx context.Context, _ *zap.SugaredLogger, ctrl *initController) error {
+		managers.set(ctrl, true)
+		defer managers.set(ctrl, false)
+
+		r.setState(ctrl, stateRunning, nil)
+		<-ctx.Done()
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = r.Start(ctx)
+	}()
+
+	for !r.isLeading() {
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return managers
+}
+
+// controllerKeys returns the keys of all running init controllers.
+func controllerKeys(r *Reconciler) []string {
+	r.ctrlLock.Lock()
+	defer r.ctrlLock.Unlock()
+
+	keys := []string{}
+	for key := range r.ctrlCancels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+func reconcileTarget(t *testing.T, r *Reconciler, ref targetRef) {
+	t.Helper()
+
+	if _, err := r.Reconcile(context.Background(), ref.request()); err != nil {
+		t.Fatalf("Failed to reconcile %s: %v", ref, err)
+	}
+}
+
+func getTarget(t *testing.T, client ctrlruntimeclient.Client, name string) *initializationv1alpha1.InitTarget {
+	t.Helper()
+
+	target := &initializationv1alpha1.InitTarget{}
+	if err := client.Get(context.Background(), types.NamespacedName{Name: name}, target); err != nil {
+		t.Fatalf("Failed to get InitTarget %s: %v", name, err)
+	}
+
+	return target
+}
+
+func TestNoControllersAreStartedAfterStopping(t *testing.T) {
+	r := newTestReconciler()
+	r.log = zap.NewNop().Sugar()
+	r.settings = settings.NewStore(settings.Settings{InitWorkers: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = r.Start(ctx)
+	}()
+
+	// wait for Start to record the leader context
+	for !r.isLeading() {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	<-done
+
+	target := newTestTarget("target", time.Now())
+	r.ensureInitControllers(r.log, testRef(target), target, []resolvedWorkspaceType{newTestWorkspaceType("wst", "root:wst")})
+
+	if len(r.ctrlCancels) > 0 {
+		t.Fatalf("Expected no init controllers to be started after stopping, got %d.", len(r.ctrlCancels))
+	}
+}
+
+func TestReadyzCheck(t *testing.T) {
+	r := newTestReconciler()
+
+	target := newTestTarget("target", time.Now())
+	running := addTestController(r, testRef(target), target, newTestWorkspaceType("a", "root:a"))
+	failed := addTestController(r, testRef(target), target, newTestWorkspaceType("b", ""))
+
+	r.ctrlCancels[running].state = stateRunning
+	r.ctrlCancels[failed].state = stateFailed
+	r.ctrlCancels[failed].lastError = errors.New("WorkspaceType not found")
+	r.ctrlCancels[failed].restarts = 2
+
+	handler := &healthz.Handler{Checks: map[string]healthz.Checker{
+		ReadyzCheckName: r.readyzCheck,
+	}}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	if recorder := get("/"); recorder.Code != http.StatusOK {
+		t.Errorf("Expected the aggregated readyz to pass despite a failed init controller, got %d.", recorder.Code)
+	}
+
+	recorder := get("/" + ReadyzCheckName)
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected the init controller check to fail, got %d.", recorder.Code)
+	}
+
+	expected := "root:config/target (root:b): Failed after 2 restarts: WorkspaceType not found"
+	if body := recorder.Body.String(); !strings.Contains(body, expected) || strings.Contains(body, "root:a") {
+		t.Errorf("Expected only the failed init controller to be reported, got %q.", body)
+	}
+
+	r.ctrlCancels[failed].state = stateRunning
+
+	if recorder := get("/" + ReadyzCheckName); recorder.Code != http.StatusOK {
+		t.Errorf("Expected the init controller check to pass once all controllers run, got %d.", recorder.Code)
+	}
+}
+
+func TestNextRestartBackoff(t *testing.T) {
+	testcases := []struct {
+		previous time.Duration
+		ranFor   time.Duration
+		expected time.Duration
+	}{
+		{previous: 0, ranFor: 0, expected: restartInitialBackoff},
+		{previous: restartInitialBackoff, ranFor: time.Second, expected: 2 * restartInitialBackoff},
+		{previous: 4 * time.Minute, ranFor: time.Second, expected: restartMaxBackoff},
+		{previous: restartMaxBackoff, ranFor: time.Second, expected: restartMaxBackoff},
+		{previous: restartMaxBackoff, ranFor: restartMaxBackoff + time.Second, expected: restartInitialBackoff},
+	}
+
+	for _, tc := range testcases {
+		if backoff := nextRestartBackoff(tc.previous, tc.ranFor); backoff != tc.expected {
+			t.Errorf("Expected backoff after %v (ran for %v) to be %v, got %v.", tc.previous, tc.ranFor, tc.expected, backoff)
+		}
+	}
+}
+
+func TestSupervisorRestartsFailedManager(t *testing.T) {
+	target := newTestTarget("target", time.Now())
+	target.Spec.WorkspaceTypeReferences = []initializationv1alpha1.WorkspaceTypeReference{{Path: "root", Name: "a"}}
+
+	r := newTestReconciler()
+	r.log = zap.NewNop().Sugar()
+	client := newFakeConfigWorkspace(t, r, target)
+
+	ref := targetRef{workspace: testWorkspace, name: "supervised"}
+	key := addTestController(r, ref, target, newTestWorkspaceType("a", "root:a"))
+	ctrl := r.ctrlCancels[key]
+
+	var attempts atomic.Int32
+	failed := make(chan time.Time, 1)
+	restarted := make(chan time.Time, 1)
+	r.runManager = func(ctx context.Context, _ *zap.SugaredLogger, ctrl *initController) error {
+		// the first attempt fails, the second one keeps running
+		if attempts.Add(1) == 1 {
+			failed <- time.Now()
+			return errors.New("cannot reach kcp")
+		}
+
+		restarted <- time.Now()
+		r.setState(ctrl, stateRunning, nil)
+		<-ctx.Done()
+		return nil
+	}
+
+	// metrics identify InitTargets as "<workspace>/<name>"
+	const label = testWorkspace + "/supervised"
+	restartsBefore := testutil.ToFloat64(metrics.ManagerRestarts.WithLabelValues(label))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.supervise(ctx, r.log, ctrl)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	condition := func() metav1.Condition {
+		if err := r.updateStatus(context.Background(), client, ref, getTarget(t, client, target.Name), nil, nil); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+
+		return *meta.FindStatusCondition(getTarget(t, client, target.Name).Status.Conditions, initializationv1alpha1.InitControllerReadyCondition)
+	}
+
+	failedAt := <-failed
+
+	// wait for the supervisor to record the failure
+	for {
+		r.ctrlLock.Lock()
+		state := ctrl.state
+		r.ctrlLock.Unlock()
+
+		if state == stateFailed {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if c := condition(); c.Status != metav1.ConditionFalse || c.Reason != initializationv1alpha1.InitControllerFailedReason || !strings.Contains(c.Message, "cannot reach kcp") {
+		t.Errorf("Expected the InitControllerReady condition to report the failure, got %+v.", c)
+	}
+
+	select {
+	case restartedAt := <-restarted:
+		if waited := restartedAt.Sub(failedAt); waited < restartInitialBackoff {
+			t.Errorf("Expected the manager to be restarted after at least %v, but it was restarted after %v.", restartInitialBackoff, waited)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the failed manager to be restarted.")
+	}
+
+	// wait for the restarted manager to be reported as running
+	for {
+		r.ctrlLock.Lock()
+		state := ctrl.state
+		r.ctrlLock.Unlock()
+
+		if state == stateRunning {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if c := condition(); c.Status != metav1.ConditionTrue || c.Reason != initializationv1alpha1.InitControllerRunningReason {
+		t.Errorf("Expected the InitControllerReady condition to report the running controller, got %+v.", c)
+	}
+
+	if restarts := getTarget(t, client, target.Name).Status.ControllerRestarts; restarts != 1 {
+		t.Errorf("Expected one restart in the status, got %d.", restarts)
+	}
+
+	if restarts := testutil.ToFloat64(metrics.ManagerRestarts.WithLabelValues(label)) - restartsBefore; restarts != 1 {
+		t.Errorf("Expected one restart to be counted for %q, got %v.", label, restarts)
+	}
+}
+
+func TestMetricsOfEquallyNamedTargetsDoNotCollide(t *testing.T) {
+	r := newTestReconciler()
+
+	first := targetRef{workspace: "root:first", name: "target"}
+	second := targetRef{workspace: "root:second", name: "target"}
+
+	if label := first.String(); label != "root:first/target" {
+		t.Fatalf("Expected InitTargets to be identified as <workspace>/<name> in metrics, got %q.", label)
+	}
+
+	metrics.InitTargetSuspended.WithLabelValues(first.String()).Set(1)
+	metrics.InitTargetSuspended.WithLabelValues(second.String()).Set(1)
+
+	r.stopControllersForTarget(zap.NewNop().Sugar(), first)
+
+	if suspended := testutil.ToFloat64(metrics.InitTargetSuspended.WithLabelValues(second.String())); suspended != 1 {
+		t.Fatalf("Expected the other InitTarget to still be reported as suspended, got %v.", suspended)
+	}
+
+	metrics.InitTargetSuspended.DeleteLabelValues(second.String())
+}
+
+func TestFinalizersOfOtherAgentsAreKept(t *testing.T) {
+	selector := labels.SelectorFromSet(labels.Set{"agent": "mine"})
+
+	r := newTestReconciler()
+	r.log = zap.NewNop().Sugar()
+	r.settings = settings.NewStore(settings.Settings{InitWorkers: 1, InitTargetSelector: selector})
+
+	mine := newTestTarget("mine", time.Now())
+	controllerutil.AddFinalizer(mine, CleanupFinalizer)
+
+	foreign := newTestTarget("foreign", time.Now())
+	foreign.Labels = map[string]string{"agent": "theirs"}
+	controllerutil.AddFinalizer(foreign, CleanupFinalizer)
+
+	client := newFakeConfigWorkspace(t, r, mine, foreign)
+
+	// this agent has handled its own InitTarget before it was relabeled
+	r.markHandled(testRef(mine))
+
+	reconcileTarget(t, r, testRef(mine))
+	reconcileTarget(t, r, testRef(foreign))
+
+	if controllerutil.ContainsFinalizer(getTarget(t, client, mine.Name), CleanupFinalizer) {
+		t.Error("Expected the finalizer of the relabeled InitTarget to be removed.")
+	}
+
+	if !controllerutil.ContainsFinalizer(getTarget(t, client, foreign.Name), CleanupFinalizer) {
+		t.Error("Expected the finalizer of the InitTarget of another agent to be kept.")
+	}
+}
+
+func TestRequeueWorkspaceOnlyRequeuesMatchingTargets(t *testing.T) {
+	r := newTestReconciler()
+	r.log = zap.NewNop().Sugar()
+
+	oldTarget := newTestTarget("old", time.Now())
+	oldTarget.Labels = map[string]string{"agent": "old"}
+
+	newTarget := newTestTarget("new", time.Now())
+	newTarget.Labels = map[string]string{"agent": "new"}
+
+	foreign := newTestTarget("foreign", time.Now())
+	foreign.Labels = map[string]string{"agent": "other"}
+
+	newFakeConfigWorkspace(t, r, oldTarget, newTarget, foreign)
+
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[mcreconcile.Request]())
+	defer queue.ShutDown()
+
+	r.requeueWorkspace(context.Background(), testWorkspace, queue,
+		labels.SelectorFromSet(labels.Set{"agent": "old"}),
+		labels.SelectorFromSet(labels.Set{"agent": "new"}),
+	)
+
+	requeued := map[string]bool{}
+	for queue.Len() > 0 {
+		req, _ := queue.Get()
+		requeued[req.Name] = true
+		queue.Done(req)
+	}
+
+	if len(requeued) != 2 || !requeued[oldTarget.Name] || !requeued[newTarget.Name] {
+		t.Fatalf("Expected only the InitTargets matching the old or new selector to be requeued, got %v.", requeued)
+	}
+}
+
+func newLifecycleTarget(name string, wsts ...string) *initializationv1alpha1.InitTarget {
+	target := newTestTarget(name, time.Now())
+	for _, wst := range wsts {
+		target.Spec.WorkspaceTypeReferences = append(target.Spec.WorkspaceTypeReferences, initializationv1alpha1.WorkspaceTypeReference{Path: "root", Name: wst})
+	}
+
+	return target
+}
+
+func newLifecycleReconciler(t *testing.T, objects ...ctrlruntimeclient.Object) (*Reconciler, ctrlruntimeclient.Client, *fakeManagers) {
+	t.Helper()
+
+	r := newTestReconciler()
+	r.log = zap.NewNop().Sugar()
+	r.settings = settings.NewStore(settings.Settings{InitWorkers: 1})
+
+	client := newFakeConfigWorkspace(t, r, objects...)
+	newFakeWorkspaceTypes(t, r, "a", "b")
+	managers := startLeading(t, r)
+
+	return r, client, managers
+}
+
+func TestInitTargetLifecycle(t *testing.T) {
+	t.Run("a new InitTarget gets a finalizer and an init controller", func(t *testing.T) {
+		target := newLifecycleTarget("target", "a")
+		r, client, _ := newLifecycleReconciler(t, target)
+
+		reconcileTarget(t, r, testRef(target))
+
+		if !controllerutil.ContainsFinalizer(getTarget(t, client, target.Name), CleanupFinalizer) {
+			t.Error("Expected the finalizer to be added.")
+		}
+
+		if keys := controllerKeys(r); !slices.Equal(keys, []string{"target-uid/root/a"}) {
+			t.Errorf("Expected one init controller, got %v.", keys)
+		}
+	})
+
+	t.Run("deletion stops the init controller and removes the finalizer", func(t *testing.T) {
+		target := newLifecycleTarget("target", "a")
+		r, client, managers := newLifecycleReconciler(t, target)
+
+		reconcileTarget(t, r, testRef(target))
+
+		r.ctrlLock.Lock()
+		ctrl := r.ctrlCancels["target-uid/root/a"]
+		r.ctrlLock.Unlock()
+
+		if err := client.Delete(context.Background(), target); err != nil {
+			t.Fatalf("Failed to delete InitTarget: %v", err)
+		}
+
+		reconcileTarget(t, r, testRef(target))
+
+		if keys := controllerKeys(r); len(keys) > 0 {
+			t.Errorf("Expected the init controller to be stopped, got %v.", keys)
+		}
+
+		err := client.Get(context.Background(), types.NamespacedName{Name: target.Name}, &initializationv1alpha1.InitTarget{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("Expected the InitTarget to be gone once the finalizer was removed, got %v.", err)
+		}
+
+		managers.waitForStop(t, ctrl)
+	})
+
+	t.Run("a vanished InitTarget stops its init controllers", func(t *testing.T) {
+		r, _, _ := newLifecycleReconciler(t)
+
+		gone := newLifecycleTarget("target")
+		addTestController(r, testRef(gone), gone, newTestWorkspaceType("a", "root:a"))
+
+		// an InitTarget with the same name in another workspace is unaffected
+		other := newLifecycleTarget("target")
+		other.UID = "other-uid"
+		otherRef := targetRef{workspace: "root:other", name: other.Name}
+		addTestController(r, otherRef, other, newTestWorkspaceType("b", "root:b"))
+
+		reconcileTarget(t, r, testRef(gone))
+
+		if keys := controllerKeys(r); !slices.Equal(keys, []string{"other-uid/root/b"}) {
+			t.Errorf("Expected only the init controller of the other InitTarget to remain, got %v.", keys)
+		}
+	})
+
+	t.Run("relabeling stops the init controller", func(t *testing.T) {
+		target := newLifecycleTarget("target", "a")
+		target.Labels = map[string]string{"agent": "mine"}
+		r, client, _ := newLifecycleReconciler(t, target)
+		r.settings.Set(settings.Settings{InitWorkers: 1, InitTargetSelector: labels.SelectorFromSet(target.Labels)})
+
+		reconcileTarget(t, r, testRef(target))
+
+		target = getTarget(t, client, target.Name)
+		target.Labels = map[string]string{"agent": "theirs"}
+		if err := client.Update(context.Background(), target); err != nil {
+			t.Fatalf("Failed to relabel InitTarget: %v", err)
+		}
+
+		reconcileTarget(t, r, testRef(target))
+
+		if keys := controllerKeys(r); len(keys) > 0 {
+			t.Errorf("Expected the init controller to be stopped, got %v.", keys)
+		}
+
+		if controllerutil.ContainsFinalizer(getTarget(t, client, target.Name), CleanupFinalizer) {
+			t.Error("Expected the finalizer to be removed.")
+		}
+	})
+
+	t.Run("a changed reference restarts the init controller", func(t *testing.T) {
+		target := newLifecycleTarget("target", "a")
+		r, client, managers := newLifecycleReconciler(t, target)
+
+		reconcileTarget(t, r, testRef(target))
+
+		r.ctrlLock.Lock()
+		oldCtrl := r.ctrlCancels["target-uid/root/a"]
+		r.ctrlLock.Unlock()
+
+		target = getTarget(t, client, target.Name)
+		target.Spec.WorkspaceTypeReferences[0].Name = "b"
+		if err := client.Update(context.Background(), target); err != nil {
+			t.Fatalf("Failed to update InitTarget: %v", err)
+		}
+
+		reconcileTarget(t, r, testRef(target))
+
+		if keys := controllerKeys(r); !slices.Equal(keys, []string{"target-uid/root/b"}) {
+			t.Errorf("Expected the init controller to be replaced, got %v.", keys)
+		}
+
+		managers.waitForStop(t, oldCtrl)
+	})
+}
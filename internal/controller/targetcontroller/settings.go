@@ -0,0 +1,97 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetcontroller
+
+import (
+	"context"
+	"slices"
+
+	"go.uber.org/zap"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
+)
+
+// targetFilter returns the current InitTarget selector.
+func (r *Reconciler) targetFilter() labels.Selector {
+	if selector := r.settings.Get().InitTargetSelector; selector != nil {
+		return selector
+	}
+
+	return labels.Everything()
+}
+
+// settingsSource returns a source that requeues the InitTargets in all config
+// and tenant workspaces which match the old or the new selector whenever the
+// agent's settings change, so that InitTargets which started or stopped
+// matching the selector are picked up or released, and init controllers using
+// the default number of workers are restarted if it has changed.
+func (r *Reconciler) settingsSource() source.TypedSource[mcreconcile.Request] {
+	return source.TypedFunc[mcreconcile.Request](func(ctx context.Context, queue workqueue.TypedRateLimitingInterface[mcreconcile.Request]) error {
+		changes, unsubscribe := r.settings.Subscribe()
+		oldFilter := r.targetFilter()
+
+		go func() {
+			defer unsubscribe()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-changes:
+					newFilter := r.targetFilter()
+					for _, ws := range r.workspaces.names() {
+						r.requeueWorkspace(ctx, ws, queue, oldFilter, newFilter)
+					}
+					oldFilter = newFilter
+				}
+			}
+		}()
+
+		return nil
+	})
+}
+
+// requeueWorkspace enqueues all InitTargets in the given config workspace that
+// match any of the given selectors.
+func (r *Reconciler) requeueWorkspace(ctx context.Context, workspace string, queue workqueue.TypedRateLimitingInterface[mcreconcile.Request], selectors ...labels.Selector) {
+	log := r.log.With("configws", workspace)
+
+	cluster, err := r.mgr.GetCluster(ctx, workspace)
+	if err != nil {
+		log.Warnw("Failed to get config workspace after the settings have changed", zap.Error(err))
+		return
+	}
+
+	targets := &initializationv1alpha1.InitTargetList{}
+	if err := cluster.GetCache().List(ctx, targets); err != nil {
+		log.Warnw("Failed to list InitTargets after the settings have changed", zap.Error(err))
+		return
+	}
+
+	for _, target := range targets.Items {
+		if slices.ContainsFunc(selectors, func(selector labels.Selector) bool {
+			return selector.Matches(labels.Set(target.Labels))
+		}) {
+			queue.Add(targetRef{workspace: workspace, name: target.Name}.request())
+		}
+	}
+}
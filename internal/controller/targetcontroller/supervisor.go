@@ -0,0 +1,396 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetcontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kcp-dev/init-agent/internal/controller/initcontroller"
+	"github.com/kcp-dev/init-agent/internal/metrics"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+	kcptenancyinitialization "github.com/kcp-dev/sdk/apis/tenancy/initialization"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	mcreconcile "sigs.k8s.io/multicluster-runtime/pkg/reconcile"
+)
+
+const (
+	restartInitialBackoff = 1 * time.Second
+	restartMaxBackoff     = 5 * time.Minute
+)
+
+type controllerState string
+
+const (
+	stateStarting controllerState = "Starting"
+	stateRunning  controllerState = "Running"
+	stateFailed   controllerState = "Failed"
+)
+
+// supervise runs the multicluster manager for the given controller and
+// restarts it with an exponential backoff whenever it fails, until the
+// context is cancelled.
+func (r *Reconciler) supervise(ctx context.Context, log *zap.SugaredLogger, ctrl *initController) {
+	var backoff time.Duration
+
+	for {
+		started := time.Now()
+		err := r.runManager(ctx, log, ctrl)
+
+		// the controller was stopped on purpose
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			err = errors.New("manager stopped unexpectedly")
+		}
+
+		backoff = nextRestartBackoff(backoff, time.Since(started))
+
+		log.Errorw("Init controller failed, restarting", "backoff", backoff, zap.Error(err))
+		r.setState(ctrl, stateFailed, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		r.ctrlLock.Lock()
+		ctrl.restarts++
+		r.ctrlLock.Unlock()
+
+		metrics.ManagerRestarts.WithLabelValues(ctrl.target.String()).Inc()
+		r.setState(ctrl, stateStarting, nil)
+	}
+}
+
+// nextRestartBackoff returns how long to wait before restarting a manager that
+// failed after running for the given duration, based on the previous backoff
+// (zero if the manager has not failed before).
+func nextRestartBackoff(previous time.Duration, ranFor time.Duration) time.Duration {
+	// a manager that ran fine for a while deserves a quick restart
+	if previous == 0 || ranFor > restartMaxBackoff {
+		return restartInitialBackoff
+	}
+
+	return min(2*previous, restartMaxBackoff)
+}
+
+// runMulticlusterManager creates a fresh manager (managers cannot be restarted
+// once they have been stopped) and runs it until it fails or the context is
+// cancelled.
+func (r *Reconciler) runMulticlusterManager(ctx context.Context, log *zap.SugaredLogger, ctrl *initController) error {
+	// fetch the WorkspaceType this controller is responsible for
+	wst, err := r.getWorkspaceType(ctx, ctrl.workspaceType)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve WorkspaceType: %w", err)
+	}
+
+	initializer := kcptenancyinitialization.InitializerForType(wst)
+	log = log.With("initializer", initializer)
+
+	log.Info("Creating new init controller…")
+
+	mgr, err := r.createMulticlusterManager(wst)
+	if err != nil {
+		return fmt.Errorf("failed to create multicluster manager: %w", err)
+	}
+
+	if err := r.newInitController(mgr, ctrl.target.String(), r.newInitTargetProvider(ctrl.target), r.newResultReporter(ctrl), initializer, ctrl.workers); err != nil {
+		return fmt.Errorf("failed to create init controller: %w", err)
+	}
+
+	// mark the controller as running once the manager has started all its runnables
+	err = mgr.GetLocalManager().Add(manager.RunnableFunc(func(ctx context.Context) error {
+		r.setState(ctrl, stateRunning, nil)
+		return nil
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to add state runnable: %w", err)
+	}
+
+	if err := mgr.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
+
+// setState updates the controller's state and triggers a reconciliation of
+// its InitTarget, so that the new state is reflected in its status.
+func (r *Reconciler) setState(ctrl *initController, state controllerState, err error) {
+	r.ctrlLock.Lock()
+	ctrl.state = state
+	ctrl.lastError = err
+	r.ctrlLock.Unlock()
+
+	r.requeue(ctrl.target)
+}
+
+// newResultReporter returns a reporter that keeps track of the most recent
+// initialization result of the controller. Its InitTarget is only requeued if
+// the outcome changed, so that successfully initializing one workspace after
+// another does not cause any status updates.
+func (r *Reconciler) newResultReporter(ctrl *initController) initcontroller.ResultReporter {
+	return func(workspace logicalcluster.Path, err error) {
+		r.ctrlLock.Lock()
+		previous := ctrl.lastResult
+		ctrl.lastResult = &initResult{workspace: workspace, err: err, time: time.Now()}
+		r.ctrlLock.Unlock()
+
+		if previous == nil || resultMessage(previous) != resultMessage(ctrl.lastResult) {
+			r.requeue(ctrl.target)
+		}
+	}
+}
+
+// maxResultMessageLength limits the length of the InitializationSucceeded
+// condition's message.
+const maxResultMessageLength = 2048
+
+func resultMessage(result *initResult) string {
+	if result.err == nil {
+		return "The most recent initialization of a workspace succeeded."
+	}
+
+	message := fmt.Sprintf("Failed to initialize workspace %s: %v", result.workspace, result.err)
+	if len(message) > maxResultMessageLength {
+		message = message[:maxResultMessageLength] + "…"
+	}
+
+	return message
+}
+
+// requeue triggers a reconciliation of the given InitTarget.
+func (r *Reconciler) requeue(ref targetRef) {
+	select {
+	case r.stateChanges <- ref:
+	default:
+		// the queue is full, the InitTarget will be updated eventually
+	}
+}
+
+// stateChangesSource returns a source that enqueues the InitTargets passed to
+// requeue.
+func (r *Reconciler) stateChangesSource() source.TypedSource[mcreconcile.Request] {
+	return source.TypedFunc[mcreconcile.Request](func(ctx context.Context, queue workqueue.TypedRateLimitingInterface[mcreconcile.Request]) error {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case ref := <-r.stateChanges:
+					queue.Add(ref.request())
+				}
+			}
+		}()
+
+		return nil
+	})
+}
+
+// updateStatus reflects the state of the InitTarget's init controllers, its
+// conflicts with other InitTargets and the WorkspaceTypes it must not use in
+// its status.
+func (r *Reconciler) updateStatus(ctx context.Context, client ctrlruntimeclient.Client, ref targetRef, target *initializationv1alpha1.InitTarget, conflicts []string, forbidden []string) error {
+	r.ctrlLock.Lock()
+	var (
+		failures []string
+		starting bool
+		restarts int32
+		ctrls    int
+		latest   *initResult
+	)
+
+	for _, ctrl := range r.ctrlCancels {
+		if ctrl.targetUID != target.UID {
+			continue
+		}
+
+		ctrls++
+		restarts += ctrl.restarts
+
+		if ctrl.lastResult != nil && (latest == nil || ctrl.lastResult.time.After(latest.time)) {
+			latest = ctrl.lastResult
+		}
+
+		switch ctrl.state {
+		case stateRunning:
+		case stateFailed:
+			failures = append(failures, fmt.Sprintf("%s:%s: %v", ctrl.workspaceType.Path, ctrl.workspaceType.Name, ctrl.lastError))
+		default:
+			starting = true
+		}
+	}
+	r.ctrlLock.Unlock()
+
+	slices.Sort(failures)
+
+	condition := metav1.Condition{
+		Type:               initializationv1alpha1.InitControllerReadyCondition,
+		ObservedGeneration: target.Generation,
+	}
+
+	switch {
+	case ctrls == 0 && len(forbidden) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = initializationv1alpha1.InitControllerForbiddenReason
+		condition.Message = "No init controller is started because the InitTarget may not use its WorkspaceTypes."
+	case ctrls == 0 && len(conflicts) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = initializationv1alpha1.InitControllerConflictReason
+		condition.Message = "No init controller is started because of conflicting InitTargets."
+	case ctrls == 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = initializationv1alpha1.InitControllerNoWorkspaceTypesReason
+		condition.Message = "The InitTarget does not refer to any WorkspaceType."
+	case len(failures) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = initializationv1alpha1.InitControllerFailedReason
+		condition.Message = fmt.Sprintf("The init controller has failed and will be restarted: %s", strings.Join(failures, "; "))
+	case starting:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = initializationv1alpha1.InitControllerStartingReason
+		condition.Message = "The init controller is starting."
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = initializationv1alpha1.InitControllerRunningReason
+		condition.Message = "The init controller is running."
+	}
+
+	oldTarget := target.DeepCopy()
+	meta.SetStatusCondition(&target.Status.Conditions, condition)
+	target.Status.ControllerRestarts = restarts
+
+	if latest != nil {
+		result := metav1.Condition{
+			Type:               initializationv1alpha1.InitializationSucceededCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: target.Generation,
+			Reason:             initializationv1alpha1.InitializationSucceededReason,
+			Message:            resultMessage(latest),
+		}
+
+		if latest.err != nil {
+			result.Status = metav1.ConditionFalse
+			result.Reason = initializationv1alpha1.InitializationFailedReason
+		}
+
+		meta.SetStatusCondition(&target.Status.Conditions, result)
+	}
+
+	if target.Spec.Suspend {
+		meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{
+			Type:               initializationv1alpha1.SuspendedCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: target.Generation,
+			Reason:             initializationv1alpha1.SuspendedReason,
+			Message:            "Initialization of new workspaces is paused.",
+		})
+		metrics.InitTargetSuspended.WithLabelValues(ref.String()).Set(1)
+	} else {
+		meta.RemoveStatusCondition(&target.Status.Conditions, initializationv1alpha1.SuspendedCondition)
+		metrics.InitTargetSuspended.WithLabelValues(ref.String()).Set(0)
+	}
+
+	if len(conflicts) > 0 {
+		meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{
+			Type:               initializationv1alpha1.ConflictCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: target.Generation,
+			Reason:             initializationv1alpha1.DuplicateInitializerReason,
+			Message:            strings.Join(conflicts, " "),
+		})
+	} else {
+		meta.RemoveStatusCondition(&target.Status.Conditions, initializationv1alpha1.ConflictCondition)
+	}
+
+	if len(forbidden) > 0 {
+		meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{
+			Type:               initializationv1alpha1.ForbiddenCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: target.Generation,
+			Reason:             initializationv1alpha1.WorkspaceTypeNotOwnedReason,
+			Message:            strings.Join(forbidden, " "),
+		})
+	} else {
+		meta.RemoveStatusCondition(&target.Status.Conditions, initializationv1alpha1.ForbiddenCondition)
+	}
+
+	if equality.Semantic.DeepEqual(oldTarget.Status, target.Status) {
+		return nil
+	}
+
+	if err := client.Status().Patch(ctx, target, ctrlruntimeclient.MergeFrom(oldTarget)); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+
+	return nil
+}
+
+// readyzCheck reports every init controller that is not running. A single
+// broken InitTarget (e.g. one whose WorkspaceType does not exist) must not take
+// the agent out of service, so this check only fails when it is queried on its
+// own (/readyz/init-controllers) and always passes as part of the aggregated
+// /readyz. The state is also reflected in the InitTargets' status.
+func (r *Reconciler) readyzCheck(req *http.Request) error {
+	if path.Base(req.URL.Path) != ReadyzCheckName {
+		return nil
+	}
+
+	r.ctrlLock.Lock()
+	var problems []string
+	for _, ctrl := range r.ctrlCancels {
+		name := fmt.Sprintf("%s (%s:%s)", ctrl.target, ctrl.workspaceType.Path, ctrl.workspaceType.Name)
+
+		switch ctrl.state {
+		case stateRunning:
+		case stateFailed:
+			problems = append(problems, fmt.Sprintf("%s: %s after %d restarts: %v", name, ctrl.state, ctrl.restarts, ctrl.lastError))
+		default:
+			problems = append(problems, fmt.Sprintf("%s: %s", name, ctrl.state))
+		}
+	}
+	r.ctrlLock.Unlock()
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	slices.Sort(problems)
+
+	return fmt.Errorf("not all init controllers are running: %s", strings.Join(problems, "; "))
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetcontroller
+
+import (
+	"fmt"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+)
+
+// TenantProvider engages the workspaces of tenants that manage their own
+// InitTargets, usually through the virtual workspace of an APIExport.
+type TenantProvider interface {
+	multicluster.Provider
+	multicluster.ProviderRunnable
+}
+
+// capTenantPriority prevents tenants from letting their workspaces jump ahead
+// of those of the agent's own InitTargets: tenants can only lower the priority
+// of their InitTargets.
+func capTenantPriority(target *initializationv1alpha1.InitTarget) {
+	target.Spec.Priority = min(target.Spec.Priority, 0)
+}
+
+// restrictToTenant returns a copy of a tenant's InitTarget without all
+// references and selectors pointing to other workspaces than the tenant's
+// own. This must happen before any WorkspaceType is retrieved, as the agent
+// would otherwise use its own permissions to look into workspaces the tenant
+// has no access to. The returned messages describe the rejected fields, but
+// deliberately do not repeat the paths or names of the foreign WorkspaceTypes.
+func restrictToTenant(tenant string, target *initializationv1alpha1.InitTarget) (*initializationv1alpha1.InitTarget, []string) {
+	restricted := target.DeepCopy()
+	spec := &restricted.Spec
+
+	var forbidden []string
+
+	isForeign := func(path string) bool {
+		return path != "" && path != tenant
+	}
+
+	if spec.WorkspaceTypeReference.Name != "" && isForeign(spec.WorkspaceTypeReference.Path) {
+		spec.WorkspaceTypeReference = initializationv1alpha1.WorkspaceTypeReference{}
+		forbidden = append(forbidden, "spec.workspaceTypeRef refers to another workspace.")
+	}
+
+	var refs []initializationv1alpha1.WorkspaceTypeReference
+	for i, ref := range spec.WorkspaceTypeReferences {
+		if isForeign(ref.Path) {
+			forbidden = append(forbidden, fmt.Sprintf("spec.workspaceTypeRefs[%d] refers to another workspace.", i))
+			continue
+		}
+
+		refs = append(refs, ref)
+	}
+	spec.WorkspaceTypeReferences = refs
+
+	if spec.WorkspaceTypeSelector != nil && isForeign(spec.WorkspaceTypeSelector.Path) {
+		spec.WorkspaceTypeSelector = nil
+		forbidden = append(forbidden, "spec.workspaceTypeSelector selects WorkspaceTypes in another workspace.")
+	}
+
+	return restricted, forbidden
+}
+
+// checkOwnership splits the resolved WorkspaceTypes of a tenant's InitTarget
+// into those that live in the tenant's own workspace and may be used, and all
+// others. A tenant must not inject objects into workspaces of types it does
+// not own, so WorkspaceTypes that could not be retrieved are rejected as well,
+// until they exist.
+func checkOwnership(tenant string, wsts []resolvedWorkspaceType) ([]resolvedWorkspaceType, []string) {
+	var (
+		allowed   []resolvedWorkspaceType
+		forbidden []string
+	)
+
+	for _, wst := range wsts {
+		if wst.cluster == logicalcluster.Name(tenant) {
+			allowed = append(allowed, wst)
+			continue
+		}
+
+		forbidden = append(forbidden, fmt.Sprintf("WorkspaceType %q was not found in the InitTarget's workspace.", wst.ref.Name))
+	}
+
+	return allowed, forbidden
+}
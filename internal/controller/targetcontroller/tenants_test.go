@@ -0,0 +1,143 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetcontroller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+func TestCheckOwnership(t *testing.T) {
+	own := newTestWorkspaceType("own", "tenant:own")
+	own.cluster = "tenant"
+
+	foreign := newTestWorkspaceType("foreign", "admin:foreign")
+	foreign.cluster = "admin"
+
+	missing := newTestWorkspaceType("missing", "")
+
+	allowed, forbidden := checkOwnership("tenant", []resolvedWorkspaceType{own, foreign, missing})
+
+	if len(allowed) != 1 || allowed[0].ref.Name != "own" {
+		t.Fatalf("Expected only the own WorkspaceType to be allowed, got %v.", allowed)
+	}
+
+	if len(forbidden) != 2 {
+		t.Fatalf("Expected the foreign and the missing WorkspaceType to be forbidden, got %v.", forbidden)
+	}
+}
+
+func TestRestrictToTenant(t *testing.T) {
+	target := &initializationv1alpha1.InitTarget{
+		Spec: initializationv1alpha1.InitTargetSpec{
+			WorkspaceTypeReference: initializationv1alpha1.WorkspaceTypeReference{Path: "root:secret", Name: "hidden"},
+			WorkspaceTypeReferences: []initializationv1alpha1.WorkspaceTypeReference{
+				{Name: "own"},
+				{Path: "tenant", Name: "also-own"},
+				{Path: "root:secret", Name: "hidden"},
+			},
+			WorkspaceTypeSelector: &initializationv1alpha1.WorkspaceTypeSelector{
+				Path:     "root:secret",
+				Selector: metav1.LabelSelector{},
+			},
+		},
+	}
+
+	restricted, forbidden := restrictToTenant("tenant", target)
+
+	spec := restricted.Spec
+	if spec.WorkspaceTypeReference.Name != "" || spec.WorkspaceTypeSelector != nil {
+		t.Fatalf("Expected the foreign reference and selector to be removed, got %+v.", spec)
+	}
+
+	var names []string
+	for _, ref := range spec.WorkspaceTypeReferences {
+		names = append(names, ref.Name)
+	}
+
+	if !slices.Equal(names, []string{"own", "also-own"}) {
+		t.Fatalf("Expected only the own references to remain, got %v.", spec.WorkspaceTypeReferences)
+	}
+
+	if len(forbidden) != 3 {
+		t.Fatalf("Expected three rejected fields, got %v.", forbidden)
+	}
+
+	for _, msg := range forbidden {
+		if strings.Contains(msg, "secret") || strings.Contains(msg, "hidden") {
+			t.Errorf("Expected message to not mention the foreign WorkspaceType, got %q.", msg)
+		}
+	}
+
+	if target.Spec.WorkspaceTypeSelector == nil {
+		t.Fatal("Expected the original InitTarget to remain unchanged.")
+	}
+}
+
+type nopAware struct{}
+
+func (nopAware) Engage(context.Context, string, cluster.Cluster) error {
+	return nil
+}
+
+func TestTenantTracking(t *testing.T) {
+	p := &workspaceProvider{
+		primary: "root:config",
+		clusters: map[string]cluster.Cluster{
+			"root:config": nil,
+		},
+		activeTenants: map[string]struct{}{},
+	}
+
+	aware := &tenantAware{provider: p, aware: nopAware{}}
+
+	if err := aware.Engage(context.Background(), "root:config", nil); err == nil {
+		t.Fatal("Expected config workspace to be rejected as a tenant.")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := aware.Engage(ctx, "tenant", nil); err != nil {
+		t.Fatalf("Failed to engage tenant: %v", err)
+	}
+
+	if !p.isTenant("tenant") || p.isTenant("root:config") {
+		t.Fatal("Expected only the engaged workspace to be a tenant.")
+	}
+
+	if names := p.names(); !slices.Equal(names, []string{"root:config", "tenant"}) {
+		t.Fatalf("Expected config and tenant workspace, got %v.", names)
+	}
+
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for p.isTenant("tenant") {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected tenant to be forgotten once it was disengaged.")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetcontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/kcp-dev/init-agent/internal/kcp"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+)
+
+// Workspaces are the config workspaces in which the agent looks for
+// InitTargets.
+type Workspaces struct {
+	// Primary is the workspace the agent's manager is targeting.
+	Primary string
+	// Additional are further config workspaces. They must not contain Primary.
+	Additional []string
+	// Tenants optionally provides the workspaces of tenants. InitTargets in
+	// these workspaces may only use WorkspaceTypes of the same workspace.
+	Tenants TenantProvider
+}
+
+var _ multicluster.Provider = &workspaceProvider{}
+
+// workspaceProvider engages the config workspaces and, optionally, the tenant
+// workspaces. The primary workspace is served by the agent's own manager,
+// every additional workspace gets its own cluster and cache.
+type workspaceProvider struct {
+	primary  string
+	clusters map[string]cluster.Cluster
+	tenants  TenantProvider
+
+	lock          sync.RWMutex
+	activeTenants map[string]struct{}
+}
+
+func newWorkspaceProvider(localMgr manager.Manager, clusterClient kcp.ClusterClient, workspaces Workspaces) (*workspaceProvider, error) {
+	p := &workspaceProvider{
+		primary: workspaces.Primary,
+		clusters: map[string]cluster.Cluster{
+			workspaces.Primary: localMgr,
+		},
+		tenants:       workspaces.Tenants,
+		activeTenants: map[string]struct{}{},
+	}
+
+	for _, ws := range workspaces.Additional {
+		if _, exists := p.clusters[ws]; exists {
+			return nil, fmt.Errorf("config workspace %q is configured more than once", ws)
+		}
+
+		cl, err := cluster.New(clusterClient.ClusterConfig(logicalcluster.Name(ws)), func(o *cluster.Options) {
+			o.Scheme = localMgr.GetScheme()
+			o.Logger = localMgr.GetLogger()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cluster for %q: %w", ws, err)
+		}
+
+		p.clusters[ws] = cl
+	}
+
+	return p, nil
+}
+
+// Start starts the additional clusters and the tenant provider, engages all
+// config workspaces and blocks until the context is cancelled or one of the
+// clusters has failed.
+func (p *workspaceProvider) Start(ctx context.Context, aware multicluster.Aware) error {
+	errs := make(chan error, len(p.clusters)+1)
+
+	for name, cl := range p.clusters {
+		// the primary cluster is the local manager, which is already running
+		if name != p.primary {
+			go func() {
+				if err := cl.Start(ctx); err != nil {
+					errs <- fmt.Errorf("config workspace %q has failed: %w", name, err)
+				}
+			}()
+		}
+
+		if err := aware.Engage(ctx, name, cl); err != nil {
+			return fmt.Errorf("failed to engage config workspace %q: %w", name, err)
+		}
+	}
+
+	if p.tenants != nil {
+		go func() {
+			if err := p.tenants.Start(ctx, &tenantAware{provider: p, aware: aware}); err != nil {
+				errs <- fmt.Errorf("tenant provider has failed: %w", err)
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
+
+// Get returns the cluster for the given config or tenant workspace.
+func (p *workspaceProvider) Get(ctx context.Context, clusterName string) (cluster.Cluster, error) {
+	if cl, ok := p.clusters[clusterName]; ok {
+		return cl, nil
+	}
+
+	if p.isTenant(clusterName) {
+		return p.tenants.Get(ctx, clusterName)
+	}
+
+	return nil, multicluster.ErrClusterNotFound
+}
+
+// IndexField adds an indexer to all config and tenant workspaces.
+func (p *workspaceProvider) IndexField(ctx context.Context, obj ctrlruntimeclient.Object, field string, extractValue ctrlruntimeclient.IndexerFunc) error {
+	for name, cl := range p.clusters {
+		if err := cl.GetFieldIndexer().IndexField(ctx, obj, field, extractValue); err != nil {
+			return fmt.Errorf("failed to add index to config workspace %q: %w", name, err)
+		}
+	}
+
+	if p.tenants != nil {
+		return p.tenants.IndexField(ctx, obj, field, extractValue)
+	}
+
+	return nil
+}
+
+// names returns the names of all config workspaces and currently engaged
+// tenant workspaces.
+func (p *workspaceProvider) names() []string {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	names := make([]string, 0, len(p.clusters)+len(p.activeTenants))
+	for name := range p.clusters {
+		names = append(names, name)
+	}
+
+	for name := range p.activeTenants {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// isTenant returns true if the given cluster is an engaged tenant workspace.
+func (p *workspaceProvider) isTenant(clusterName string) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	_, ok := p.activeTenants[clusterName]
+
+	return ok
+}
+
+// tenantAware keeps track of the tenant workspaces that are engaged by the
+// tenant provider.
+type tenantAware struct {
+	provider *workspaceProvider
+	aware    multicluster.Aware
+}
+
+func (t *tenantAware) Engage(ctx context.Context, name string, cl cluster.Cluster) error {
+	p := t.provider
+
+	// config workspaces are trusted, they must not be treated like tenants
+	if _, exists := p.clusters[name]; exists {
+		return errors.New("workspace is already configured as a config workspace")
+	}
+
+	p.lock.Lock()
+	p.activeTenants[name] = struct{}{}
+	p.lock.Unlock()
+
+	if err := t.aware.Engage(ctx, name, cl); err != nil {
+		p.forgetTenant(name)
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		p.forgetTenant(name)
+	}()
+
+	return nil
+}
+
+func (p *workspaceProvider) forgetTenant(name string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.activeTenants, name)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetcontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kcp-dev/init-agent/internal/kcp"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
+	kcptenancyinitialization "github.com/kcp-dev/sdk/apis/tenancy/initialization"
+	kcptenancyv1alpha1 "github.com/kcp-dev/sdk/apis/tenancy/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// workspaceTypeResyncInterval is how often InitTargets with a WorkspaceType
+// selector are reconciled to pick up WorkspaceTypes that started or stopped
+// matching the selector.
+const workspaceTypeResyncInterval = 30 * time.Second
+
+// resolvedWorkspaceType is a single WorkspaceType that an InitTarget refers to.
+type resolvedWorkspaceType struct {
+	// ref always has a path set.
+	ref initializationv1alpha1.WorkspaceTypeReference
+
+	// initializer is empty if the WorkspaceType could not be retrieved; in
+	// this case the supervisor will report the error.
+	initializer kcpcorev1alpha1.LogicalClusterInitializer
+
+	// cluster is the logical cluster the WorkspaceType lives in; it is empty
+	// if the WorkspaceType could not be retrieved.
+	cluster logicalcluster.Name
+}
+
+// resolveWorkspaceTypes returns all WorkspaceTypes the InitTarget refers to,
+// either directly or via its selector. WorkspaceTypes that are referenced
+// multiple times (even via different paths) are only returned once.
+func (r *Reconciler) resolveWorkspaceTypes(ctx context.Context, log *zap.SugaredLogger, target *initializationv1alpha1.InitTarget) ([]resolvedWorkspaceType, error) {
+	ownCluster := kcp.ClusterNameFromObject(target).String()
+
+	refs := []initializationv1alpha1.WorkspaceTypeReference{}
+	if target.Spec.WorkspaceTypeReference.Name != "" {
+		refs = append(refs, target.Spec.WorkspaceTypeReference)
+	}
+	refs = append(refs, target.Spec.WorkspaceTypeReferences...)
+
+	var result []resolvedWorkspaceType
+
+	for _, ref := range refs {
+		if ref.Path == "" {
+			ref.Path = ownCluster
+		}
+
+		resolved := resolvedWorkspaceType{ref: ref}
+
+		wst, err := r.getWorkspaceType(ctx, ref)
+		if err != nil {
+			log.Debugw("Failed to resolve WorkspaceType", "path", ref.Path, "name", ref.Name, zap.Error(err))
+		} else {
+			resolved.initializer = kcptenancyinitialization.InitializerForType(wst)
+			resolved.cluster = kcp.ClusterNameFromObject(wst)
+		}
+
+		result = append(result, resolved)
+	}
+
+	if sel := target.Spec.WorkspaceTypeSelector; sel != nil {
+		path := sel.Path
+		if path == "" {
+			path = ownCluster
+		}
+
+		wsts, err := r.listWorkspaceTypes(ctx, path, &sel.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list WorkspaceTypes in %s: %w", path, err)
+		}
+
+		for _, wst := range wsts {
+			result = append(result, resolvedWorkspaceType{
+				ref:         initializationv1alpha1.WorkspaceTypeReference{Path: path, Name: wst.Name},
+				initializer: kcptenancyinitialization.InitializerForType(&wst),
+				cluster:     kcp.ClusterNameFromObject(&wst),
+			})
+		}
+	}
+
+	return dedupeWorkspaceTypes(result), nil
+}
+
+func dedupeWorkspaceTypes(wsts []resolvedWorkspaceType) []resolvedWorkspaceType {
+	seenRefs := map[initializationv1alpha1.WorkspaceTypeReference]struct{}{}
+	seenInitializers := map[kcpcorev1alpha1.LogicalClusterInitializer]struct{}{}
+
+	result := []resolvedWorkspaceType{}
+	for _, wst := range wsts {
+		if _, seen := seenRefs[wst.ref]; seen {
+			continue
+		}
+		seenRefs[wst.ref] = struct{}{}
+
+		if wst.initializer != "" {
+			if _, seen := seenInitializers[wst.initializer]; seen {
+				continue
+			}
+			seenInitializers[wst.initializer] = struct{}{}
+		}
+
+		result = append(result, wst)
+	}
+
+	return result
+}
+
+func (r *Reconciler) workspaceTypeClient(path string) (ctrlruntimeclient.Client, error) {
+	scheme := runtime.NewScheme()
+
+	if err := kcptenancyv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to register local scheme %s: %w", kcptenancyv1alpha1.SchemeGroupVersion, err)
+	}
+
+	wstClient, err := r.clusterClient.Cluster(logicalcluster.Name(path), scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for WorkspaceType cluster: %w", err)
+	}
+
+	return wstClient, nil
+}
+
+func (r *Reconciler) getWorkspaceType(ctx context.Context, ref initializationv1alpha1.WorkspaceTypeReference) (*kcptenancyv1alpha1.WorkspaceType, error) {
+	wstClient, err := r.workspaceTypeClient(ref.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	wst := &kcptenancyv1alpha1.WorkspaceType{}
+	if err := wstClient.Get(ctx, types.NamespacedName{Name: ref.Name}, wst); err != nil {
+		return nil, err
+	}
+
+	return wst, nil
+}
+
+func (r *Reconciler) listWorkspaceTypes(ctx context.Context, path string, selector *metav1.LabelSelector) ([]kcptenancyv1alpha1.WorkspaceType, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+
+	wstClient, err := r.workspaceTypeClient(path)
+	if err != nil {
+		return nil, err
+	}
+
+	wsts := &kcptenancyv1alpha1.WorkspaceTypeList{}
+	if err := wstClient.List(ctx, wsts, ctrlruntimeclient.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		return nil, err
+	}
+
+	return wsts.Items, nil
+}
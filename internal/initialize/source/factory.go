@@ -20,9 +20,12 @@ import (
 	"context"
 	"errors"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/kcp-dev/init-agent/internal/initialize"
 	"github.com/kcp-dev/init-agent/internal/initialize/source/inittemplate"
 	"github.com/kcp-dev/init-agent/internal/log"
+	"github.com/kcp-dev/init-agent/internal/tracing"
 	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
 
 	"github.com/kcp-dev/logicalcluster/v3"
@@ -41,14 +44,20 @@ func NewFactory(deps Dependencies) *Factory {
 }
 
 func (f *Factory) NewForInitSource(ctx context.Context, cluster logicalcluster.Name, src initializationv1alpha1.InitSource) (initialize.ManifestsSource, error) {
+	ctx, span := tracing.Start(ctx, "source.NewForInitSource")
+	defer span.End()
+
 	logger := log.FromContext(ctx)
 
 	switch {
 	case src.Template != nil:
 		logger.Debugw("Initializing InitTemplate source", "init-template", src.Template.Name)
-		return f.NewInitTemplate(ctx, cluster, src.Template)
+		span.SetAttributes(attribute.String("source.type", "template"), attribute.String("source.name", src.Template.Name))
+
+		s, err := f.NewInitTemplate(ctx, cluster, src.Template)
+		return s, tracing.RecordError(span, err)
 	default:
-		return nil, errors.New("no known source configured")
+		return nil, tracing.RecordError(span, errors.New("no known source configured"))
 	}
 }
 
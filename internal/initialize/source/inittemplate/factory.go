@@ -18,6 +18,7 @@ package inittemplate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/kcp-dev/init-agent/internal/initialize"
@@ -28,10 +29,17 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
 )
 
 type Dependencies struct {
 	ClusterClient kcp.ClusterClient
+
+	// Tenants optionally provides access to tenant workspaces, whose
+	// InitTemplates are read through the provider (e.g. an APIExport's
+	// virtual workspace) instead of directly.
+	Tenants multicluster.Provider
 }
 
 func Factory(ctx context.Context, deps Dependencies, cluster logicalcluster.Name, src *initializationv1alpha1.TemplateInitSource) (initialize.ManifestsSource, error) {
@@ -41,7 +49,7 @@ func Factory(ctx context.Context, deps Dependencies, cluster logicalcluster.Name
 		return nil, fmt.Errorf("failed to register local scheme %s: %w", initializationv1alpha1.SchemeGroupVersion, err)
 	}
 
-	client, err := deps.ClusterClient.Cluster(cluster, scheme)
+	client, err := templateClient(ctx, deps, cluster, scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -54,3 +62,18 @@ func Factory(ctx context.Context, deps Dependencies, cluster logicalcluster.Name
 
 	return NewFromInitTemplate(tpl)
 }
+
+func templateClient(ctx context.Context, deps Dependencies, cluster logicalcluster.Name, scheme *runtime.Scheme) (ctrlruntimeclient.Reader, error) {
+	if deps.Tenants != nil {
+		tenant, err := deps.Tenants.Get(ctx, cluster.String())
+		if err == nil {
+			return tenant.GetClient(), nil
+		}
+
+		if !errors.Is(err, multicluster.ErrClusterNotFound) {
+			return nil, fmt.Errorf("failed to get tenant workspace: %w", err)
+		}
+	}
+
+	return deps.ClusterClient.Cluster(cluster, scheme)
+}
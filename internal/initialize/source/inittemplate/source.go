@@ -18,14 +18,19 @@ package inittemplate
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"html/template"
 
 	"github.com/Masterminds/sprig/v3"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/kcp-dev/init-agent/internal/initialize"
 	"github.com/kcp-dev/init-agent/internal/kcp"
 	"github.com/kcp-dev/init-agent/internal/manifest"
+	"github.com/kcp-dev/init-agent/internal/tracing"
 	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
 
 	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
@@ -34,7 +39,8 @@ import (
 )
 
 type source struct {
-	tpl *template.Template
+	tpl     *template.Template
+	version string
 }
 
 func New(tplString string) (initialize.ManifestsSource, error) {
@@ -43,13 +49,26 @@ func New(tplString string) (initialize.ManifestsSource, error) {
 		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	return &source{tpl: tpl}, nil
+	return &source{tpl: tpl, version: templateHash(tplString)}, nil
 }
 
 func NewFromInitTemplate(initTemplate *initializationv1alpha1.InitTemplate) (initialize.ManifestsSource, error) {
 	return New(initTemplate.Spec.Template)
 }
 
+// templateHash returns the first 16 hex characters of the SHA-256 hash of the
+// template.
+func templateHash(tplString string) string {
+	hash := sha256.Sum256([]byte(tplString))
+	return hex.EncodeToString(hash[:])[:16]
+}
+
+// Version returns a hash of the template, which changes whenever the template
+// is changed.
+func (b *source) Version() string {
+	return b.version
+}
+
 type initTemplateRenderContext struct {
 	// ClusterName is the internal cluster identifier (e.g. "34hg2j4gh24jdfgf")
 	// of the cluster that is being initialized.
@@ -73,11 +92,21 @@ func (b *source) render(cluster *kcpcorev1alpha1.LogicalCluster) ([]byte, error)
 	return buf.Bytes(), nil
 }
 
-func (b *source) Manifests(cluster *kcpcorev1alpha1.LogicalCluster) ([]*unstructured.Unstructured, error) {
+func (b *source) Manifests(ctx context.Context, cluster *kcpcorev1alpha1.LogicalCluster) ([]*unstructured.Unstructured, error) {
+	_, span := tracing.Start(ctx, "inittemplate.Render")
+	defer span.End()
+
 	rendered, err := b.render(cluster)
 	if err != nil {
-		return nil, err
+		return nil, tracing.RecordError(span, err)
 	}
 
-	return manifest.ParseYAML(rendered)
+	objects, err := manifest.ParseYAML(rendered)
+	if err != nil {
+		return nil, tracing.RecordError(span, err)
+	}
+
+	span.SetAttributes(attribute.Int("objects", len(objects)))
+
+	return objects, nil
 }
@@ -17,11 +17,20 @@ limitations under the License.
 package initialize
 
 import (
+	"context"
+
 	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 type ManifestsSource interface {
-	Manifests(cluster *kcpcorev1alpha1.LogicalCluster) ([]*unstructured.Unstructured, error)
+	Manifests(ctx context.Context, cluster *kcpcorev1alpha1.LogicalCluster) ([]*unstructured.Unstructured, error)
+}
+
+// VersionedSource is optionally implemented by ManifestsSources whose content
+// can change over time. The version identifies the current content and is
+// recorded on all objects created from the source.
+type VersionedSource interface {
+	Version() string
 }
@@ -0,0 +1,30 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package initprovider contains a multicluster-runtime provider for the
+initializingworkspaces virtual workspace of a WorkspaceType. Unlike the
+upstream provider, all providers created by the same Pool share a single
+WorkspaceType informer per workspace, so that running many init controllers
+does not require one watch and cache per WorkspaceType.
+
+The LogicalCluster watch and the scoped caches of the initializing clusters
+are still created per provider, i.e. per initializer: kcp serves the
+initializingworkspaces virtual workspace under a separate URL for every
+initializer, and only that URL exposes the clusters that still carry the
+initializer. The scoped caches only exist while a cluster is initializing.
+*/
+package initprovider
@@ -0,0 +1,148 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initprovider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/kcp-dev/init-agent/internal/kcp"
+	"github.com/kcp-dev/init-agent/internal/metrics"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
+	kcptenancyv1alpha1 "github.com/kcp-dev/sdk/apis/tenancy/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// Pool creates providers and shares their WorkspaceType informers. A Pool is
+// safe for concurrent use.
+type Pool struct {
+	clusterClient kcp.ClusterClient
+	scheme        *runtime.Scheme
+	log           *zap.SugaredLogger
+
+	lock      sync.Mutex
+	informers map[logicalcluster.Name]*sharedCache
+}
+
+// sharedCache is a cache for WorkspaceTypes in a single workspace, which is
+// stopped once the last provider using it has released it.
+type sharedCache struct {
+	cache  cache.Cache
+	cancel context.CancelFunc
+	refs   int
+}
+
+// NewPool returns a new pool. All providers share the same scheme, which
+// includes the kcp core and tenancy APIs.
+func NewPool(clusterClient kcp.ClusterClient, log *zap.SugaredLogger) (*Pool, error) {
+	scheme := runtime.NewScheme()
+
+	if err := kcptenancyv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to register scheme %s: %w", kcptenancyv1alpha1.SchemeGroupVersion, err)
+	}
+
+	if err := kcpcorev1alpha1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to register scheme %s: %w", kcpcorev1alpha1.SchemeGroupVersion, err)
+	}
+
+	return &Pool{
+		clusterClient: clusterClient,
+		scheme:        scheme,
+		log:           log.Named("initprovider"),
+		informers:     map[logicalcluster.Name]*sharedCache{},
+	}, nil
+}
+
+// Scheme returns the scheme shared by all providers of this pool.
+func (p *Pool) Scheme() *runtime.Scheme {
+	return p.scheme
+}
+
+// NewProvider returns a provider for all workspaces that are currently being
+// initialized by the given WorkspaceType's initializer.
+func (p *Pool) NewProvider(wst *kcptenancyv1alpha1.WorkspaceType) *Provider {
+	cluster := kcp.ClusterNameFromObject(wst)
+
+	return newProvider(p, cluster, wst.Name)
+}
+
+// acquire returns the shared WorkspaceType cache for the given workspace and
+// starts it if it is not running yet. Every call must be followed by a call
+// to release.
+func (p *Pool) acquire(cluster logicalcluster.Name) (cache.Cache, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if shared, ok := p.informers[cluster]; ok {
+		shared.refs++
+		return shared.cache, nil
+	}
+
+	c, err := cache.New(p.clusterClient.ClusterConfig(cluster), cache.Options{
+		Scheme: p.scheme,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache: %w", err)
+	}
+
+	// The cache must outlive the provider that happened to start it, so it
+	// gets its own context.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		if err := c.Start(ctx); err != nil {
+			p.log.Warnw("WorkspaceType cache has stopped", "cluster", cluster, zap.Error(err))
+		}
+	}()
+
+	p.informers[cluster] = &sharedCache{
+		cache:  c,
+		cancel: cancel,
+		refs:   1,
+	}
+	metrics.WorkspaceTypeInformers.Set(float64(len(p.informers)))
+
+	return c, nil
+}
+
+// release gives up one reference to the shared cache for the given workspace
+// and stops the cache once it is not used anymore.
+func (p *Pool) release(cluster logicalcluster.Name) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	shared, ok := p.informers[cluster]
+	if !ok {
+		return
+	}
+
+	shared.refs--
+	if shared.refs > 0 {
+		return
+	}
+
+	shared.cancel()
+	delete(p.informers, cluster)
+	metrics.WorkspaceTypeInformers.Set(float64(len(p.informers)))
+}
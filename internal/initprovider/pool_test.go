@@ -0,0 +1,223 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kcp-dev/init-agent/internal/kcp"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+	kcptenancyv1alpha1 "github.com/kcp-dev/sdk/apis/tenancy/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+func TestPoolSharesCaches(t *testing.T) {
+	pool, err := NewPool(kcp.NewClusterClient(&rest.Config{Host: "https://127.0.0.1:1"}), zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+
+	a := logicalcluster.Name("a")
+	b := logicalcluster.Name("b")
+
+	first, err := pool.acquire(a)
+	if err != nil {
+		t.Fatalf("Failed to acquire cache: %v", err)
+	}
+
+	second, err := pool.acquire(a)
+	if err != nil {
+		t.Fatalf("Failed to acquire cache: %v", err)
+	}
+
+	if first != second {
+		t.Fatal("Expected providers for the same workspace to share a cache.")
+	}
+
+	other, err := pool.acquire(b)
+	if err != nil {
+		t.Fatalf("Failed to acquire cache: %v", err)
+	}
+
+	if other == first {
+		t.Fatal("Expected providers for different workspaces to use different caches.")
+	}
+
+	pool.release(a)
+	pool.release(b)
+
+	if _, ok := pool.informers[a]; !ok {
+		t.Fatal("Expected cache to be kept while it is still in use.")
+	}
+
+	if _, ok := pool.informers[b]; ok {
+		t.Fatal("Expected unused cache to be stopped.")
+	}
+
+	pool.release(a)
+
+	if len(pool.informers) != 0 {
+		t.Fatalf("Expected all caches to be stopped, but %d are left.", len(pool.informers))
+	}
+}
+
+// fakeKCP serves just enough of kcp's API for WorkspaceType informers and
+// counts the watches that are opened.
+type fakeKCP struct {
+	watches atomic.Int32
+}
+
+func (f *fakeKCP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// the cluster is irrelevant, every workspace looks the same
+	path := r.URL.Path
+	if strings.HasPrefix(path, "/clusters/") {
+		parts := strings.SplitN(path, "/", 4)
+		path = "/"
+		if len(parts) == 4 {
+			path += parts[3]
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch path {
+	case "/api":
+		fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+	case "/api/v1":
+		fmt.Fprint(w, `{"kind":"APIResourceList","groupVersion":"v1","resources":[]}`)
+	case "/apis":
+		fmt.Fprint(w, `{"kind":"APIGroupList","groups":[{"name":"tenancy.kcp.io","versions":[{"groupVersion":"tenancy.kcp.io/v1alpha1","version":"v1alpha1"}],"preferredVersion":{"groupVersion":"tenancy.kcp.io/v1alpha1","version":"v1alpha1"}}]}`)
+	case "/apis/tenancy.kcp.io/v1alpha1":
+		fmt.Fprint(w, `{"kind":"APIResourceList","groupVersion":"tenancy.kcp.io/v1alpha1","resources":[{"name":"workspacetypes","singularName":"workspacetype","namespaced":false,"kind":"WorkspaceType","verbs":["get","list","watch"]}]}`)
+	case "/apis/tenancy.kcp.io/v1alpha1/workspacetypes":
+		if r.URL.Query().Get("watch") != "true" {
+			fmt.Fprint(w, `{"kind":"WorkspaceTypeList","apiVersion":"tenancy.kcp.io/v1alpha1","metadata":{"resourceVersion":"1"},"items":[]}`)
+			return
+		}
+
+		f.watches.Add(1)
+		defer f.watches.Add(-1)
+
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+type nopAware struct{}
+
+func (nopAware) Engage(context.Context, string, cluster.Cluster) error {
+	return nil
+}
+
+// TestWorkspaceTypeWatches compares the number of WorkspaceType watches that
+// are needed for many init controllers, once with a shared pool and once with
+// a pool per provider, which is what a provider with its own informer does.
+func TestWorkspaceTypeWatches(t *testing.T) {
+	const workspaceTypes = 20
+
+	testcases := []struct {
+		name            string
+		shared          bool
+		expectedWatches int32
+	}{
+		{name: "one informer per provider", shared: false, expectedWatches: workspaceTypes},
+		{name: "shared informer", shared: true, expectedWatches: 1},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			kcpServer := &fakeKCP{}
+			server := httptest.NewServer(kcpServer)
+			defer server.Close()
+
+			clusterClient := kcp.NewClusterClient(&rest.Config{Host: server.URL})
+
+			newPool := func() *Pool {
+				pool, err := NewPool(clusterClient, zap.NewNop().Sugar())
+				if err != nil {
+					t.Fatalf("Failed to create pool: %v", err)
+				}
+
+				return pool
+			}
+
+			shared := newPool()
+
+			ctx, cancel := context.WithCancel(t.Context())
+			var wg sync.WaitGroup
+
+			for i := range workspaceTypes {
+				pool := shared
+				if !tc.shared {
+					pool = newPool()
+				}
+
+				wst := &kcptenancyv1alpha1.WorkspaceType{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        fmt.Sprintf("type-%d", i),
+						Annotations: map[string]string{logicalcluster.AnnotationKey: "types"},
+					},
+				}
+
+				provider := pool.NewProvider(wst)
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := provider.Start(ctx, nopAware{}); err != nil {
+						t.Errorf("Provider failed: %v", err)
+					}
+				}()
+			}
+
+			deadline := time.Now().Add(10 * time.Second)
+			for kcpServer.watches.Load() < tc.expectedWatches && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			// give any superfluous watches the chance to show up
+			time.Sleep(200 * time.Millisecond)
+
+			watches := kcpServer.watches.Load()
+			t.Logf("%d WorkspaceTypes need %d WorkspaceType watches.", workspaceTypes, watches)
+
+			if watches != tc.expectedWatches {
+				t.Errorf("Expected %d watches, got %d.", tc.expectedWatches, watches)
+			}
+
+			cancel()
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+
+	"github.com/kcp-dev/logicalcluster/v3"
+	mcpcache "github.com/kcp-dev/multicluster-provider/pkg/cache"
+	"github.com/kcp-dev/multicluster-provider/pkg/events/recorder"
+	mcpprovider "github.com/kcp-dev/multicluster-provider/pkg/provider"
+	kcpcorev1alpha1 "github.com/kcp-dev/sdk/apis/core/v1alpha1"
+	kcptenancyv1alpha1 "github.com/kcp-dev/sdk/apis/tenancy/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/multicluster-runtime/pkg/clusters"
+	"sigs.k8s.io/multicluster-runtime/pkg/multicluster"
+)
+
+var _ multicluster.Provider = &Provider{}
+var _ multicluster.ProviderRunnable = &Provider{}
+
+// Provider engages every logical cluster that is exposed by the
+// initializingworkspaces virtual workspace(s) of a single WorkspaceType. It
+// follows the WorkspaceType's virtual workspace URLs using the pool's shared
+// informer and runs one generic kcp provider per URL.
+type Provider struct {
+	pool     *Pool
+	cluster  logicalcluster.Name
+	name     string
+	clusters *mcpprovider.Clusters
+	log      *zap.SugaredLogger
+
+	lock      sync.Mutex
+	endpoints map[string]*endpoint
+}
+
+// endpoint is a generic provider running for a single virtual workspace URL.
+type endpoint struct {
+	cancel context.CancelFunc
+}
+
+func newProvider(pool *Pool, clusterName logicalcluster.Name, name string) *Provider {
+	return &Provider{
+		pool:      pool,
+		cluster:   clusterName,
+		name:      name,
+		clusters:  ptr.To(clusters.New[cluster.Cluster]()),
+		log:       pool.log.With("cluster", clusterName, "workspacetype", name),
+		endpoints: map[string]*endpoint{},
+	}
+}
+
+// Start runs the provider until the context is cancelled or the WorkspaceType
+// is deleted.
+func (p *Provider) Start(ctx context.Context, aware multicluster.Aware) error {
+	c, err := p.pool.acquire(p.cluster)
+	if err != nil {
+		return err
+	}
+	defer p.pool.release(p.cluster)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	informer, err := c.GetInformer(ctx, &kcptenancyv1alpha1.WorkspaceType{})
+	if err != nil {
+		return fmt.Errorf("failed to get WorkspaceType informer: %w", err)
+	}
+
+	update := func(obj any) {
+		if wst, ok := obj.(*kcptenancyv1alpha1.WorkspaceType); ok && wst.Name == p.name {
+			p.update(ctx, aware, wst)
+		}
+	}
+
+	handler, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: update,
+		UpdateFunc: func(_, newObj any) {
+			update(newObj)
+		},
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+
+			if wst, ok := obj.(*kcptenancyv1alpha1.WorkspaceType); ok && wst.Name == p.name {
+				cancel(errors.New("WorkspaceType was deleted"))
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add event handler: %w", err)
+	}
+
+	<-ctx.Done()
+
+	if err := informer.RemoveEventHandler(handler); err != nil {
+		p.log.Warnw("Failed to remove event handler", zap.Error(err))
+	}
+
+	p.stopEndpoints()
+
+	// the provider was stopped on purpose
+	if cause := context.Cause(ctx); !errors.Is(cause, context.Canceled) {
+		return cause
+	}
+
+	return nil
+}
+
+// update makes sure that exactly one generic provider is running for each of
+// the WorkspaceType's initializingworkspaces URLs.
+func (p *Provider) update(ctx context.Context, aware multicluster.Aware, wst *kcptenancyv1alpha1.WorkspaceType) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	current := map[string]struct{}{}
+
+	for _, vw := range wst.Status.VirtualWorkspaces {
+		// the list also contains the URLs of the terminatingworkspaces virtual workspace
+		if !strings.Contains(vw.URL, "/initializingworkspaces/") {
+			continue
+		}
+
+		current[vw.URL] = struct{}{}
+
+		if _, exists := p.endpoints[vw.URL]; exists {
+			continue
+		}
+
+		if err := p.startEndpoint(ctx, aware, vw.URL); err != nil {
+			p.log.Errorw("Failed to start provider", "url", vw.URL, zap.Error(err))
+		}
+	}
+
+	for url, ep := range p.endpoints {
+		if _, exists := current[url]; !exists {
+			p.log.Infow("Stopping provider for removed endpoint", "url", url)
+			ep.cancel()
+			delete(p.endpoints, url)
+		}
+	}
+}
+
+// startEndpoint starts a generic provider for a single virtual workspace URL.
+// The caller must hold the lock.
+func (p *Provider) startEndpoint(ctx context.Context, aware multicluster.Aware, url string) error {
+	cfg := rest.CopyConfig(p.pool.clusterClient.ClusterConfig(p.cluster))
+	cfg.Host = url
+
+	logger := zapr.NewLogger(p.log.With("url", url).Desugar())
+
+	prov, err := mcpprovider.New(cfg, p.clusters, mcpprovider.Options{
+		ObjectToWatch: &kcpcorev1alpha1.LogicalCluster{},
+		Scheme:        p.pool.scheme,
+		Log:           &logger,
+
+		// The virtual workspace does not offer anything but LogicalClusters on
+		// its wildcard endpoint, so every cluster needs its own scoped cache.
+		NewCluster: func(cfg *rest.Config, clusterName logicalcluster.Name, wildcardCA mcpcache.WildcardCache, scheme *runtime.Scheme, _ *recorder.Provider) (*mcpcache.ScopedCluster, error) {
+			return mcpcache.NewScopedInitializingCluster(cfg, clusterName, wildcardCA, scheme)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpointCtx, cancel := context.WithCancel(ctx)
+	ep := &endpoint{cancel: cancel}
+	p.endpoints[url] = ep
+
+	go func() {
+		if err := prov.Start(endpointCtx, aware); err != nil && endpointCtx.Err() == nil {
+			p.log.Errorw("Provider has failed", "url", url, zap.Error(err))
+
+			// forget the endpoint, so that the next update of the WorkspaceType
+			// retries it
+			p.lock.Lock()
+			if p.endpoints[url] == ep {
+				delete(p.endpoints, url)
+			}
+			p.lock.Unlock()
+			cancel()
+		}
+	}()
+
+	return nil
+}
+
+func (p *Provider) stopEndpoints() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for url, ep := range p.endpoints {
+		ep.cancel()
+		delete(p.endpoints, url)
+	}
+}
+
+// Get returns the cluster with the given name.
+func (p *Provider) Get(ctx context.Context, clusterName string) (cluster.Cluster, error) {
+	return p.clusters.Get(ctx, clusterName)
+}
+
+// IndexField adds an indexer to all current and future clusters.
+func (p *Provider) IndexField(ctx context.Context, obj ctrlruntimeclient.Object, field string, extractValue ctrlruntimeclient.IndexerFunc) error {
+	return p.clusters.IndexField(ctx, obj, field, extractValue)
+}
@@ -120,16 +120,25 @@ func NewFromOptions(o Options) *zap.Logger {
 	return New(o.Debug, o.Format)
 }
 
+// Level returns the log level for the given debug setting.
+func Level(debug bool) zapcore.Level {
+	if debug {
+		return zap.DebugLevel
+	}
+
+	return zap.InfoLevel
+}
+
 func New(debug bool, format Format) *zap.Logger {
+	return NewWithLevel(zap.NewAtomicLevelAt(Level(debug)), format)
+}
+
+// NewWithLevel creates a new logger whose level can be changed at runtime
+// using the given level.
+func NewWithLevel(lvl zap.AtomicLevel, format Format) *zap.Logger {
 	// this basically mimics New<type>Config, but with a custom sink
 	sink := zapcore.AddSync(os.Stderr)
 
-	// Level - We only support setting Info+ or Debug+
-	lvl := zap.NewAtomicLevelAt(zap.InfoLevel)
-	if debug {
-		lvl = zap.NewAtomicLevelAt(zap.DebugLevel)
-	}
-
 	encCfg := zap.NewProductionEncoderConfig()
 	// Having a dateformat makes it more easy to look at logs outside of something like Kibana
 	encCfg.TimeKey = "time"
@@ -19,9 +19,14 @@ package manifest
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/kcp-dev/init-agent/internal/log"
+	"github.com/kcp-dev/init-agent/internal/tracing"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -30,33 +35,182 @@ import (
 )
 
 type Applier interface {
-	Apply(ctx context.Context, client ctrlruntimeclient.Client, objs []*unstructured.Unstructured) (requeue bool, err error)
+	// Apply applies the given objects and returns the per-object results. An
+	// error is only returned if the objects could not be processed at all;
+	// errors for individual objects are part of the result (see Result.Err).
+	Apply(ctx context.Context, client ctrlruntimeclient.Client, objs []*unstructured.Unstructured, opts ApplyOptions) (*Result, error)
 }
 
-type applier struct{}
+// ApplyOptions can be used to customize a single Apply call.
+type ApplyOptions struct {
+	// Sorter overrides the applier's default sorter.
+	Sorter *Sorter
+
+	// ContinueOnError makes the applier attempt every object instead of
+	// stopping at the first failing one. Objects that depend on a failed object
+	// are skipped.
+	ContinueOnError bool
 
-func NewApplier() Applier {
-	return &applier{}
+	// Provenance, if set, is recorded on every object that is created.
+	Provenance *Provenance
 }
 
-func (a *applier) Apply(ctx context.Context, client ctrlruntimeclient.Client, objs []*unstructured.Unstructured) (requeue bool, err error) {
-	SortObjectsByHierarchy(objs)
+type applier struct {
+	sorter *Sorter
+}
 
-	for _, object := range objs {
-		err := a.applyObject(ctx, client, object)
-		if err != nil {
-			if errors.Is(err, &meta.NoKindMatchError{}) {
-				return true, nil
+// NewApplier returns an applier that uses the given sorter unless overridden
+// in the ApplyOptions.
+func NewApplier(sorter *Sorter) Applier {
+	return &applier{sorter: sorter}
+}
+
+// Apply groups the objects into waves and applies one wave after another. If
+// a wave cannot be completed at this time (because a resource is not yet
+// available, an object failed or is not yet ready), no later waves are applied.
+func (a *applier) Apply(ctx context.Context, client ctrlruntimeclient.Client, objs []*unstructured.Unstructured, opts ApplyOptions) (*Result, error) {
+	ctx, span := tracing.Start(ctx, "manifest.Apply", trace.WithAttributes(attribute.Int("objects", len(objs))))
+	defer span.End()
+
+	result, err := a.apply(ctx, client, objs, opts)
+	if err != nil {
+		return nil, tracing.RecordError(span, err)
+	}
+
+	span.SetAttributes(attribute.String("summary", result.Summary()))
+
+	return result, nil
+}
+
+func (a *applier) apply(ctx context.Context, client ctrlruntimeclient.Client, objs []*unstructured.Unstructured, opts ApplyOptions) (*Result, error) {
+	sorter := a.sorter
+	if opts.Sorter != nil {
+		sorter = opts.Sorter
+	}
+
+	waves, err := GroupObjectsByWave(objs, sorter)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx)
+	result := &Result{}
+	crds := crdIndex(objs)
+
+	// keeps track of all objects that could not be applied, so that objects
+	// depending on them can be skipped
+	unavailable := map[objectKey]ObjectReference{}
+
+	// reason why all remaining objects are not attempted anymore
+	stopReason := ""
+
+	for _, wave := range waves {
+		waveComplete := true
+
+		for _, object := range wave.Objects {
+			if stopReason != "" {
+				result.add(object, ObjectPending, stopReason, nil)
+				waveComplete = false
+				continue
+			}
+
+			if dep, ok := unavailableDependency(object, crds, unavailable); ok {
+				result.add(object, ObjectSkipped, fmt.Sprintf("depends on %s", dep), nil)
+				unavailable[keyOf(object)] = newObjectReference(object)
+				waveComplete = false
+				continue
+			}
+
+			created, err := a.applyObject(ctx, client, object, opts.Provenance)
+
+			switch {
+			case err == nil && created:
+				result.add(object, ObjectCreated, "", nil)
+
+			case err == nil:
+				result.add(object, ObjectExisted, "", nil)
+
+			case errors.Is(err, &meta.NoKindMatchError{}):
+				logger.Debugw("Resource not yet available, waiting before continuing", "wave", wave.Index, "obj-gvk", object.GroupVersionKind())
+				result.add(object, ObjectDeferred, "kind is not yet available", nil)
+				unavailable[keyOf(object)] = newObjectReference(object)
+				waveComplete = false
+
+				if !opts.ContinueOnError {
+					stopReason = fmt.Sprintf("waiting for %s", newObjectReference(object))
+				}
+
+			default:
+				result.add(object, ObjectFailed, err.Error(), err)
+				unavailable[keyOf(object)] = newObjectReference(object)
+				waveComplete = false
+
+				if !opts.ContinueOnError {
+					stopReason = fmt.Sprintf("%s failed", newObjectReference(object))
+				}
+			}
+		}
+
+		if !waveComplete {
+			if stopReason == "" {
+				stopReason = fmt.Sprintf("wave %d is not complete", wave.Index)
 			}
+			continue
+		}
 
-			return false, err
+		notReady, err := a.notReadyObjects(ctx, client, wave)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(notReady) > 0 {
+			logger.Debugw("Wave is not yet ready, waiting before continuing", "wave", wave.Index)
+			result.NotReady = notReady
+			stopReason = fmt.Sprintf("wave %d is not ready", wave.Index)
 		}
 	}
 
-	return false, nil
+	return result, nil
 }
 
-func (a *applier) applyObject(ctx context.Context, client ctrlruntimeclient.Client, obj *unstructured.Unstructured) error {
+// unavailableDependency returns the first dependency of the given object that
+// could not be applied.
+func unavailableDependency(obj *unstructured.Unstructured, crds map[string]objectKey, unavailable map[objectKey]ObjectReference) (ObjectReference, bool) {
+	for _, dep := range objectDependencies(obj, crds) {
+		if ref, ok := unavailable[dep]; ok {
+			return ref, true
+		}
+	}
+
+	return ObjectReference{}, false
+}
+
+// notReadyObjects checks all objects in the wave that have opted into readiness
+// checking and returns those that are not ready yet.
+func (a *applier) notReadyObjects(ctx context.Context, client ctrlruntimeclient.Client, wave Wave) ([]ObjectReference, error) {
+	var notReady []ObjectReference
+
+	for _, object := range wave.Objects {
+		if !waitForReady(object) {
+			continue
+		}
+
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(object.GroupVersionKind())
+
+		if err := client.Get(ctx, ctrlruntimeclient.ObjectKeyFromObject(object), current); err != nil {
+			return nil, fmt.Errorf("failed to check readiness of %s %q: %w", object.GetKind(), object.GetName(), err)
+		}
+
+		if !IsReady(current) {
+			notReady = append(notReady, newObjectReference(object))
+		}
+	}
+
+	return notReady, nil
+}
+
+func (a *applier) applyObject(ctx context.Context, client ctrlruntimeclient.Client, obj *unstructured.Unstructured, provenance *Provenance) (created bool, err error) {
 	gvk := obj.GroupVersionKind()
 
 	key := ctrlruntimeclient.ObjectKeyFromObject(obj).String()
@@ -66,11 +220,25 @@ func (a *applier) applyObject(ctx context.Context, client ctrlruntimeclient.Clie
 	logger := log.FromContext(ctx)
 	logger.Debugw("Applying object", "obj-key", key, "obj-gvk", gvk)
 
+	ctx, span := tracing.Start(ctx, "manifest.ApplyObject", trace.WithAttributes(
+		attribute.String("object.gvk", gvk.String()),
+		attribute.String("object.key", key),
+	))
+	defer span.End()
+
+	if provenance != nil {
+		provenance.Stamp(obj)
+	}
+
 	if err := client.Create(ctx, obj); err != nil {
 		if !apierrors.IsAlreadyExists(err) {
-			return err
+			return false, tracing.RecordError(span, err)
 		}
+
+		span.SetAttributes(attribute.Bool("object.created", false))
+		return false, nil
 	}
 
-	return nil
+	span.SetAttributes(attribute.Bool("object.created", true))
+	return true, nil
 }
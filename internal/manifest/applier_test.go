@@ -0,0 +1,254 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestApply(t *testing.T) {
+	testcases := []struct {
+		name            string
+		continueOnError bool
+		expected        map[string]ObjectStatus
+		requeue         bool
+		failed          bool
+	}{
+		{
+			name: "stop at first error",
+			expected: map[string]ObjectStatus{
+				"namespace":   ObjectCreated,
+				"broken":      ObjectFailed,
+				"healthy":     ObjectPending,
+				"in-broken":   ObjectPending,
+				"widget":      ObjectPending,
+				"late-config": ObjectPending,
+			},
+			failed: true,
+		},
+		{
+			name:            "continue on error",
+			continueOnError: true,
+			expected: map[string]ObjectStatus{
+				"namespace":   ObjectCreated,
+				"broken":      ObjectFailed,
+				"healthy":     ObjectCreated,
+				"in-broken":   ObjectSkipped,
+				"widget":      ObjectDeferred,
+				"late-config": ObjectPending,
+			},
+			failed: true,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace := newUnstructured("v1", "Namespace", "namespace")
+			broken := newUnstructured("v1", "Namespace", "broken")
+
+			healthy := newUnstructured("v1", "ConfigMap", "healthy")
+			healthy.SetNamespace("namespace")
+
+			inBroken := newUnstructured("v1", "ConfigMap", "in-broken")
+			inBroken.SetNamespace("broken")
+
+			widget := newUnstructured("example.com/v1", "Widget", "widget")
+			lateConfig := withWave(newUnstructured("v1", "ConfigMap", "late-config"), "1")
+
+			client := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, client ctrlruntimeclient.WithWatch, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+					switch obj.GetName() {
+					case "broken":
+						return errors.New("something went wrong")
+					case "widget":
+						return &meta.NoKindMatchError{GroupKind: obj.GetObjectKind().GroupVersionKind().GroupKind()}
+					default:
+						return client.Create(ctx, obj, opts...)
+					}
+				},
+			}).Build()
+
+			objects := []*unstructured.Unstructured{lateConfig, widget, inBroken, healthy, namespace, broken}
+
+			result, err := NewApplier(DefaultSorter()).Apply(t.Context(), client, objects, ApplyOptions{
+				ContinueOnError: tt.continueOnError,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(result.Objects) != len(tt.expected) {
+				t.Fatalf("Expected %d results, got %d.", len(tt.expected), len(result.Objects))
+			}
+
+			for _, obj := range result.Objects {
+				if expected := tt.expected[obj.Object.Name]; obj.Status != expected {
+					t.Errorf("Expected %s to be %s, but is %s (%s).", obj.Object, expected, obj.Status, obj.Reason)
+				}
+			}
+
+			if tt.requeue != result.Requeue() {
+				t.Errorf("Expected requeue=%v, got %v.", tt.requeue, result.Requeue())
+			}
+
+			if tt.failed != (result.Err() != nil) {
+				t.Errorf("Expected failed=%v, got error %v.", tt.failed, result.Err())
+			}
+		})
+	}
+}
+
+func TestApplyTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	objects := []*unstructured.Unstructured{
+		newUnstructured("v1", "Namespace", "namespace"),
+		newUnstructured("v1", "ConfigMap", "config"),
+	}
+	objects[1].SetNamespace("namespace")
+
+	client := fake.NewClientBuilder().Build()
+
+	if _, err := NewApplier(DefaultSorter()).Apply(t.Context(), client, objects, ApplyOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+
+	var applySpan *tracetest.SpanStub
+	objectSpans := 0
+	for i, span := range spans {
+		switch span.Name {
+		case "manifest.Apply":
+			applySpan = &spans[i]
+		case "manifest.ApplyObject":
+			objectSpans++
+		}
+	}
+
+	if applySpan == nil {
+		t.Fatal("Expected a manifest.Apply span.")
+	}
+
+	if objectSpans != len(objects) {
+		t.Fatalf("Expected %d object spans, got %d.", len(objects), objectSpans)
+	}
+
+	for _, span := range spans {
+		if span.Name == "manifest.ApplyObject" && span.Parent.SpanID() != applySpan.SpanContext.SpanID() {
+			t.Errorf("Expected object span to be a child of the apply span.")
+		}
+	}
+}
+
+func TestApplyProvenance(t *testing.T) {
+	existing := newUnstructured("v1", "ConfigMap", "existing")
+	existing.SetNamespace("default")
+
+	client := fake.NewClientBuilder().WithObjects(existing.DeepCopy()).Build()
+
+	created := newUnstructured("v1", "ConfigMap", "created")
+	created.SetNamespace("default")
+	created.SetLabels(map[string]string{"app": "demo"})
+
+	provenance := &Provenance{
+		ManagedBy:         "kcp-init-agent",
+		InitTargetCluster: "abc123",
+		InitTarget:        "my-target",
+		SourceIndex:       2,
+		SourceTemplate:    "my-template",
+		SourceVersion:     "0123456789abcdef",
+	}
+
+	objects := []*unstructured.Unstructured{created, existing}
+	if _, err := NewApplier(DefaultSorter()).Apply(t.Context(), client, objects, ApplyOptions{Provenance: provenance}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(created.GroupVersionKind())
+	if err := client.Get(t.Context(), ctrlruntimeclient.ObjectKeyFromObject(created), current); err != nil {
+		t.Fatalf("Failed to get created object: %v", err)
+	}
+
+	expectedLabels := map[string]string{
+		"app":                               "demo",
+		"app.kubernetes.io/managed-by":      "kcp-init-agent",
+		"initialization.kcp.io/init-target": "my-target",
+	}
+	if labels := current.GetLabels(); !reflect.DeepEqual(labels, expectedLabels) {
+		t.Errorf("Expected labels %v, got %v.", expectedLabels, labels)
+	}
+
+	expectedAnnotations := map[string]string{
+		"initialization.kcp.io/init-target":         "my-target",
+		"initialization.kcp.io/init-target-cluster": "abc123",
+		"initialization.kcp.io/source-index":        "2",
+		"initialization.kcp.io/source-template":     "my-template",
+		"initialization.kcp.io/source-version":      "0123456789abcdef",
+	}
+	if annotations := current.GetAnnotations(); !reflect.DeepEqual(annotations, expectedAnnotations) {
+		t.Errorf("Expected annotations %v, got %v.", expectedAnnotations, annotations)
+	}
+
+	// objects that already existed are not modified
+	current = &unstructured.Unstructured{}
+	current.SetGroupVersionKind(existing.GroupVersionKind())
+	if err := client.Get(t.Context(), ctrlruntimeclient.ObjectKeyFromObject(existing), current); err != nil {
+		t.Fatalf("Failed to get existing object: %v", err)
+	}
+
+	if labels := current.GetLabels(); len(labels) > 0 {
+		t.Errorf("Expected existing object to remain unlabelled, got %v.", labels)
+	}
+}
+
+func TestProvenanceSkipsInvalidLabelValues(t *testing.T) {
+	obj := newUnstructured("v1", "ConfigMap", "config")
+
+	provenance := &Provenance{
+		InitTarget: "a." + strings.Repeat("x", 63),
+	}
+	provenance.Stamp(obj)
+
+	if labels := obj.GetLabels(); len(labels) > 0 {
+		t.Errorf("Expected no labels, got %v.", labels)
+	}
+
+	if name := obj.GetAnnotations()["initialization.kcp.io/init-target"]; name != provenance.InitTarget {
+		t.Errorf("Expected InitTarget annotation %q, got %q.", provenance.InitTarget, name)
+	}
+}
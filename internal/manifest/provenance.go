@@ -0,0 +1,92 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"maps"
+	"strconv"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Provenance describes where objects come from. The applier records it as
+// labels and annotations on every object it creates, so that objects created
+// by the init-agent can later be found and told apart from all others.
+type Provenance struct {
+	// ManagedBy is the value of the app.kubernetes.io/managed-by label. The
+	// label is not set if this is empty.
+	ManagedBy string
+
+	// InitTargetCluster and InitTarget identify the InitTarget that the
+	// objects belong to.
+	InitTargetCluster string
+	InitTarget        string
+
+	// SourceIndex is the index of the init source in the InitTarget.
+	SourceIndex int
+
+	// SourceTemplate is the name of the InitTemplate the objects were
+	// rendered from, if any.
+	SourceTemplate string
+
+	// SourceVersion identifies the content of the source, if known.
+	SourceVersion string
+}
+
+// Stamp places the provenance labels and annotations on the given object,
+// replacing existing values for the same keys.
+func (p *Provenance) Stamp(obj *unstructured.Unstructured) {
+	labels := map[string]string{}
+	if p.ManagedBy != "" {
+		labels[initializationv1alpha1.ManagedByLabel] = p.ManagedBy
+	}
+
+	if len(validation.IsValidLabelValue(p.InitTarget)) == 0 {
+		labels[initializationv1alpha1.InitTargetLabel] = p.InitTarget
+	}
+
+	annotations := map[string]string{
+		initializationv1alpha1.InitTargetAnnotation:        p.InitTarget,
+		initializationv1alpha1.InitTargetClusterAnnotation: p.InitTargetCluster,
+		initializationv1alpha1.SourceIndexAnnotation:       strconv.Itoa(p.SourceIndex),
+	}
+
+	if p.SourceTemplate != "" {
+		annotations[initializationv1alpha1.SourceTemplateAnnotation] = p.SourceTemplate
+	}
+
+	if p.SourceVersion != "" {
+		annotations[initializationv1alpha1.SourceVersionAnnotation] = p.SourceVersion
+	}
+
+	existingLabels := obj.GetLabels()
+	if existingLabels == nil {
+		existingLabels = map[string]string{}
+	}
+	maps.Copy(existingLabels, labels)
+	obj.SetLabels(existingLabels)
+
+	existingAnnotations := obj.GetAnnotations()
+	if existingAnnotations == nil {
+		existingAnnotations = map[string]string{}
+	}
+	maps.Copy(existingAnnotations, annotations)
+	obj.SetAnnotations(existingAnnotations)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// kindsWithoutStatus are well-known kinds that have no notion of readiness and
+// are therefore ready as soon as they exist.
+var kindsWithoutStatus = sets.New(
+	"configmap",
+	"secret",
+	"serviceaccount",
+	"service",
+	"role.rbac.authorization.k8s.io",
+	"rolebinding.rbac.authorization.k8s.io",
+	"clusterrole.rbac.authorization.k8s.io",
+	"clusterrolebinding.rbac.authorization.k8s.io",
+)
+
+// IsReady determines whether the given object (as returned by the kcp API)
+// is ready. Well-known kinds like CRDs and APIBindings have dedicated checks
+// and well-known kinds without a status (like ConfigMaps) are always ready.
+// All other objects are ready once their status reflects the current
+// generation and their Ready (or, if not present, Available) condition is
+// True; objects without any of these conditions are not ready.
+func IsReady(obj *unstructured.Unstructured) bool {
+	gk := strings.ToLower(obj.GroupVersionKind().GroupKind().String())
+
+	switch gk {
+	case "customresourcedefinition.apiextensions.k8s.io":
+		return hasTrueCondition(obj, "Established")
+
+	case "apibinding.apis.kcp.io":
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		return phase == "Bound"
+
+	case "namespace":
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		return phase == "Active"
+	}
+
+	if kindsWithoutStatus.Has(gk) {
+		return true
+	}
+
+	// the controller has not yet processed the latest spec
+	if observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration"); found && observed < obj.GetGeneration() {
+		return false
+	}
+
+	for _, condType := range []string{"Ready", "Available"} {
+		if cond, exists := findCondition(obj, condType); exists {
+			// the condition is outdated
+			if observed, ok := cond["observedGeneration"].(int64); ok && observed < obj.GetGeneration() {
+				return false
+			}
+
+			status, _ := cond["status"].(string)
+			return status == "True"
+		}
+	}
+
+	return false
+}
+
+func hasTrueCondition(obj *unstructured.Unstructured, condType string) bool {
+	cond, _ := findCondition(obj, condType)
+	status, _ := cond["status"].(string)
+	return status == "True"
+}
+
+func findCondition(obj *unstructured.Unstructured, condType string) (map[string]any, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+
+	for _, c := range conditions {
+		cond, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if t, _ := cond["type"].(string); t == condType {
+			return cond, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func withStatus(obj *unstructured.Unstructured, generation int64, status map[string]any) *unstructured.Unstructured {
+	obj.SetGeneration(generation)
+	if status != nil {
+		obj.Object["status"] = status
+	}
+
+	return obj
+}
+
+func condition(condType, status string) map[string]any {
+	return map[string]any{"type": condType, "status": status}
+}
+
+func TestIsReady(t *testing.T) {
+	testcases := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		expected bool
+	}{
+		{
+			name:     "ConfigMaps have no status and are always ready",
+			obj:      newUnstructured("v1", "ConfigMap", "test"),
+			expected: true,
+		},
+		{
+			name:     "RoleBindings have no status and are always ready",
+			obj:      newUnstructured("rbac.authorization.k8s.io/v1", "RoleBinding", "test"),
+			expected: true,
+		},
+		{
+			name:     "CRD without status",
+			obj:      newUnstructured("apiextensions.k8s.io/v1", "CustomResourceDefinition", "test"),
+			expected: false,
+		},
+		{
+			name: "established CRD",
+			obj: withStatus(newUnstructured("apiextensions.k8s.io/v1", "CustomResourceDefinition", "test"), 1, map[string]any{
+				"conditions": []any{condition("Established", "True")},
+			}),
+			expected: true,
+		},
+		{
+			name:     "APIBinding without status",
+			obj:      newUnstructured("apis.kcp.io/v1alpha1", "APIBinding", "test"),
+			expected: false,
+		},
+		{
+			name:     "bound APIBinding",
+			obj:      withStatus(newUnstructured("apis.kcp.io/v1alpha1", "APIBinding", "test"), 1, map[string]any{"phase": "Bound"}),
+			expected: true,
+		},
+		{
+			name:     "terminating Namespace",
+			obj:      withStatus(newUnstructured("v1", "Namespace", "test"), 0, map[string]any{"phase": "Terminating"}),
+			expected: false,
+		},
+		{
+			name:     "active Namespace",
+			obj:      withStatus(newUnstructured("v1", "Namespace", "test"), 0, map[string]any{"phase": "Active"}),
+			expected: true,
+		},
+		{
+			name:     "object without status",
+			obj:      newUnstructured("cert-manager.io/v1", "Issuer", "test"),
+			expected: false,
+		},
+		{
+			name:     "object with empty status",
+			obj:      withStatus(newUnstructured("cert-manager.io/v1", "Issuer", "test"), 1, map[string]any{}),
+			expected: false,
+		},
+		{
+			name: "object with unrelated conditions",
+			obj: withStatus(newUnstructured("cert-manager.io/v1", "Issuer", "test"), 1, map[string]any{
+				"conditions": []any{condition("Progressing", "True")},
+			}),
+			expected: false,
+		},
+		{
+			name: "ready object",
+			obj: withStatus(newUnstructured("cert-manager.io/v1", "Issuer", "test"), 1, map[string]any{
+				"conditions": []any{condition("Ready", "True")},
+			}),
+			expected: true,
+		},
+		{
+			name: "Ready condition takes precedence over Available",
+			obj: withStatus(newUnstructured("apps/v1", "Deployment", "test"), 1, map[string]any{
+				"conditions": []any{condition("Available", "True"), condition("Ready", "False")},
+			}),
+			expected: false,
+		},
+		{
+			name: "available object",
+			obj: withStatus(newUnstructured("apps/v1", "Deployment", "test"), 1, map[string]any{
+				"conditions": []any{condition("Available", "True")},
+			}),
+			expected: true,
+		},
+		{
+			name: "status of an older generation",
+			obj: withStatus(newUnstructured("apps/v1", "Deployment", "test"), 2, map[string]any{
+				"observedGeneration": int64(1),
+				"conditions":         []any{condition("Available", "True")},
+			}),
+			expected: false,
+		},
+		{
+			name: "condition of an older generation",
+			obj: withStatus(newUnstructured("cert-manager.io/v1", "Issuer", "test"), 2, map[string]any{
+				"conditions": []any{map[string]any{"type": "Ready", "status": "True", "observedGeneration": int64(1)}},
+			}),
+			expected: false,
+		},
+		{
+			name: "status of the current generation",
+			obj: withStatus(newUnstructured("apps/v1", "Deployment", "test"), 2, map[string]any{
+				"observedGeneration": int64(2),
+				"conditions":         []any{map[string]any{"type": "Available", "status": "True", "observedGeneration": int64(2)}},
+			}),
+			expected: true,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			if ready := IsReady(tt.obj); ready != tt.expected {
+				t.Fatalf("Expected ready=%v, got %v.", tt.expected, ready)
+			}
+		})
+	}
+}
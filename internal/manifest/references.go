@@ -0,0 +1,297 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type objectKey struct {
+	groupKind string
+	namespace string
+	name      string
+}
+
+func keyOf(obj *unstructured.Unstructured) objectKey {
+	return objectKey{
+		groupKind: groupKindKey(obj),
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+	}
+}
+
+// sortByReferences reorders the (already weight-sorted) objects so that every
+// object comes after all objects it references. Among all objects whose
+// dependencies are satisfied, the one that came first is picked, so that the
+// existing order is kept as much as possible. Reference cycles are broken by
+// falling back to the existing order.
+func sortByReferences(objects []*unstructured.Unstructured) {
+	index := map[objectKey]int{}
+	for i, obj := range objects {
+		index[keyOf(obj)] = i
+	}
+
+	crds := crdIndex(objects)
+
+	deps := make([][]int, len(objects))
+	for i, obj := range objects {
+		for _, ref := range objectDependencies(obj, crds) {
+			if j, ok := index[ref]; ok && j != i {
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+
+	done := make([]bool, len(objects))
+	sorted := make([]*unstructured.Unstructured, 0, len(objects))
+
+	satisfied := func(i int) bool {
+		for _, dep := range deps[i] {
+			if !done[dep] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for len(sorted) < len(objects) {
+		next := -1
+		for i := range objects {
+			if !done[i] && satisfied(i) {
+				next = i
+				break
+			}
+		}
+
+		// cycle detected, fall back to the first remaining object
+		if next == -1 {
+			for i := range objects {
+				if !done[i] {
+					next = i
+					break
+				}
+			}
+		}
+
+		done[next] = true
+		sorted = append(sorted, objects[next])
+	}
+
+	copy(objects, sorted)
+}
+
+// crdIndex maps the "kind.group" of every CRD in the given objects to the
+// CRD's key.
+func crdIndex(objects []*unstructured.Unstructured) map[string]objectKey {
+	crds := map[string]objectKey{}
+
+	for _, obj := range objects {
+		if groupKindKey(obj) == "customresourcedefinition.apiextensions.k8s.io" {
+			group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+			crds[strings.ToLower(kind+"."+group)] = keyOf(obj)
+		}
+	}
+
+	return crds
+}
+
+// objectDependencies returns the keys of all objects that the given object
+// depends on, i.e. the objects it references plus its CRD, if the CRD is
+// part of the given index.
+func objectDependencies(obj *unstructured.Unstructured, crds map[string]objectKey) []objectKey {
+	deps := objectReferences(obj)
+
+	if crd, ok := crds[groupKindKey(obj)]; ok {
+		deps = append(deps, crd)
+	}
+
+	return deps
+}
+
+// objectReferences returns the keys of all objects that the given object
+// references and which should therefore be created first.
+func objectReferences(obj *unstructured.Unstructured) []objectKey {
+	var refs []objectKey
+
+	namespace := obj.GetNamespace()
+	if namespace != "" {
+		refs = append(refs, objectKey{groupKind: "namespace", name: namespace})
+	}
+
+	switch groupKindKey(obj) {
+	case "rolebinding.rbac.authorization.k8s.io", "clusterrolebinding.rbac.authorization.k8s.io":
+		refs = append(refs, bindingReferences(obj)...)
+
+	case "mutatingwebhookconfiguration.admissionregistration.k8s.io", "validatingwebhookconfiguration.admissionregistration.k8s.io":
+		refs = append(refs, webhookReferences(obj)...)
+
+	case "apibinding.apis.kcp.io":
+		path, _, _ := unstructured.NestedString(obj.Object, "spec", "reference", "export", "path")
+		name, _, _ := unstructured.NestedString(obj.Object, "spec", "reference", "export", "name")
+		if path == "" && name != "" {
+			refs = append(refs, objectKey{groupKind: "apiexport.apis.kcp.io", name: name})
+		}
+
+	case "pod":
+		refs = append(refs, podSpecReferences(obj, namespace, "spec")...)
+
+	case "deployment.apps", "statefulset.apps", "daemonset.apps", "replicaset.apps", "job.batch":
+		refs = append(refs, podSpecReferences(obj, namespace, "spec", "template", "spec")...)
+
+	case "cronjob.batch":
+		refs = append(refs, podSpecReferences(obj, namespace, "spec", "jobTemplate", "spec", "template", "spec")...)
+	}
+
+	return refs
+}
+
+func bindingReferences(obj *unstructured.Unstructured) []objectKey {
+	var refs []objectKey
+
+	apiGroup, _, _ := unstructured.NestedString(obj.Object, "roleRef", "apiGroup")
+	kind, _, _ := unstructured.NestedString(obj.Object, "roleRef", "kind")
+	name, _, _ := unstructured.NestedString(obj.Object, "roleRef", "name")
+
+	if kind != "" && name != "" {
+		ref := objectKey{groupKind: strings.ToLower(kind + "." + apiGroup), name: name}
+		if strings.EqualFold(kind, "Role") {
+			ref.namespace = obj.GetNamespace()
+		}
+		refs = append(refs, ref)
+	}
+
+	subjects, _, _ := unstructured.NestedSlice(obj.Object, "subjects")
+	for _, s := range subjects {
+		subject, ok := s.(map[string]any)
+		if !ok || subject["kind"] != "ServiceAccount" {
+			continue
+		}
+
+		name, _ := subject["name"].(string)
+		namespace, _ := subject["namespace"].(string)
+		if namespace == "" {
+			namespace = obj.GetNamespace()
+		}
+
+		refs = append(refs, objectKey{groupKind: "serviceaccount", namespace: namespace, name: name})
+	}
+
+	return refs
+}
+
+func webhookReferences(obj *unstructured.Unstructured) []objectKey {
+	var refs []objectKey
+
+	webhooks, _, _ := unstructured.NestedSlice(obj.Object, "webhooks")
+	for _, w := range webhooks {
+		webhook, ok := w.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		namespace, _, _ := unstructured.NestedString(webhook, "clientConfig", "service", "namespace")
+		name, _, _ := unstructured.NestedString(webhook, "clientConfig", "service", "name")
+		if name != "" {
+			refs = append(refs, objectKey{groupKind: "service", namespace: namespace, name: name})
+		}
+	}
+
+	return refs
+}
+
+func podSpecReferences(obj *unstructured.Unstructured, namespace string, fields ...string) []objectKey {
+	spec, found, _ := unstructured.NestedMap(obj.Object, fields...)
+	if !found {
+		return nil
+	}
+
+	var refs []objectKey
+
+	addRef := func(groupKind string, name string) {
+		if name != "" {
+			refs = append(refs, objectKey{groupKind: groupKind, namespace: namespace, name: name})
+		}
+	}
+
+	sa, _, _ := unstructured.NestedString(spec, "serviceAccountName")
+	addRef("serviceaccount", sa)
+
+	volumes, _, _ := unstructured.NestedSlice(spec, "volumes")
+	for _, v := range volumes {
+		volume, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		cm, _, _ := unstructured.NestedString(volume, "configMap", "name")
+		addRef("configmap", cm)
+
+		secret, _, _ := unstructured.NestedString(volume, "secret", "secretName")
+		addRef("secret", secret)
+	}
+
+	pullSecrets, _, _ := unstructured.NestedSlice(spec, "imagePullSecrets")
+	for _, s := range pullSecrets {
+		if secret, ok := s.(map[string]any); ok {
+			name, _ := secret["name"].(string)
+			addRef("secret", name)
+		}
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(spec, field)
+		for _, c := range containers {
+			container, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			envFrom, _, _ := unstructured.NestedSlice(container, "envFrom")
+			for _, e := range envFrom {
+				source, ok := e.(map[string]any)
+				if !ok {
+					continue
+				}
+
+				cm, _, _ := unstructured.NestedString(source, "configMapRef", "name")
+				addRef("configmap", cm)
+
+				secret, _, _ := unstructured.NestedString(source, "secretRef", "name")
+				addRef("secret", secret)
+			}
+
+			env, _, _ := unstructured.NestedSlice(container, "env")
+			for _, e := range env {
+				variable, ok := e.(map[string]any)
+				if !ok {
+					continue
+				}
+
+				cm, _, _ := unstructured.NestedString(variable, "valueFrom", "configMapKeyRef", "name")
+				addRef("configmap", cm)
+
+				secret, _, _ := unstructured.NestedString(variable, "valueFrom", "secretKeyRef", "name")
+				addRef("secret", secret)
+			}
+		}
+	}
+
+	return refs
+}
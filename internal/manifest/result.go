@@ -0,0 +1,174 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+type ObjectStatus string
+
+const (
+	// ObjectCreated means the object was created.
+	ObjectCreated ObjectStatus = "Created"
+	// ObjectExisted means the object already existed and was left untouched.
+	ObjectExisted ObjectStatus = "Existed"
+	// ObjectFailed means the object could not be created.
+	ObjectFailed ObjectStatus = "Failed"
+	// ObjectDeferred means the object's kind is not (yet) known in the cluster.
+	ObjectDeferred ObjectStatus = "Deferred"
+	// ObjectSkipped means the object was not attempted because it depends on
+	// another object that could not be applied.
+	ObjectSkipped ObjectStatus = "Skipped"
+	// ObjectPending means the object was not attempted yet, because an earlier
+	// wave is not complete or because applying stopped at an earlier error.
+	ObjectPending ObjectStatus = "Pending"
+)
+
+// ObjectReference identifies a single object within a workspace.
+type ObjectReference struct {
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+}
+
+func newObjectReference(obj *unstructured.Unstructured) ObjectReference {
+	return ObjectReference{
+		GroupVersionKind: obj.GroupVersionKind(),
+		Namespace:        obj.GetNamespace(),
+		Name:             obj.GetName(),
+	}
+}
+
+func (r ObjectReference) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + r.Name
+	}
+
+	return fmt.Sprintf("%s %s", strings.ToLower(r.GroupVersionKind.GroupKind().String()), name)
+}
+
+// ObjectResult is the outcome of applying a single object.
+type ObjectResult struct {
+	Object ObjectReference
+	Status ObjectStatus
+	// Error is set for failed objects.
+	Error error
+	// Reason is a human readable explanation for non-successful objects.
+	Reason string
+}
+
+// Result is the outcome of applying all objects of a single init source.
+type Result struct {
+	Objects []ObjectResult
+	// NotReady lists objects that were applied, but did not become ready yet.
+	NotReady []ObjectReference
+}
+
+func (r *Result) add(obj *unstructured.Unstructured, status ObjectStatus, reason string, err error) {
+	r.Objects = append(r.Objects, ObjectResult{
+		Object: newObjectReference(obj),
+		Status: status,
+		Error:  err,
+		Reason: reason,
+	})
+}
+
+// Count returns the number of objects with the given status.
+func (r *Result) Count(status ObjectStatus) int {
+	count := 0
+	for _, obj := range r.Objects {
+		if obj.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Filter returns all object results with the given status.
+func (r *Result) Filter(status ObjectStatus) []ObjectResult {
+	var results []ObjectResult
+	for _, obj := range r.Objects {
+		if obj.Status == status {
+			results = append(results, obj)
+		}
+	}
+
+	return results
+}
+
+// MissingKinds returns the distinct kinds of all deferred objects, i.e. all
+// kinds that are not (yet) available in the cluster.
+func (r *Result) MissingKinds() []schema.GroupVersionKind {
+	var kinds []schema.GroupVersionKind
+	for _, obj := range r.Filter(ObjectDeferred) {
+		if !slices.Contains(kinds, obj.Object.GroupVersionKind) {
+			kinds = append(kinds, obj.Object.GroupVersionKind)
+		}
+	}
+
+	return kinds
+}
+
+// Complete returns true if all objects have been applied and are ready.
+func (r *Result) Complete() bool {
+	return r.Count(ObjectCreated)+r.Count(ObjectExisted) == len(r.Objects) && len(r.NotReady) == 0
+}
+
+// Requeue returns true if the source could not be completed yet, but there is
+// no hard error preventing it from being completed later.
+func (r *Result) Requeue() bool {
+	return !r.Complete() && r.Count(ObjectFailed) == 0
+}
+
+// Err returns an aggregate of all per-object errors, or nil if no object failed.
+func (r *Result) Err() error {
+	var errs []error
+	for _, obj := range r.Filter(ObjectFailed) {
+		errs = append(errs, fmt.Errorf("%s: %w", obj.Object, obj.Error))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Summary returns a short, human readable summary of the result.
+func (r *Result) Summary() string {
+	parts := []string{}
+	for _, status := range []ObjectStatus{ObjectCreated, ObjectExisted, ObjectFailed, ObjectDeferred, ObjectSkipped, ObjectPending} {
+		if count := r.Count(status); count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, strings.ToLower(string(status))))
+		}
+	}
+
+	if len(r.NotReady) > 0 {
+		parts = append(parts, fmt.Sprintf("%d not ready", len(r.NotReady)))
+	}
+
+	if len(parts) == 0 {
+		return "no objects"
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -18,45 +18,125 @@ package manifest
 
 import (
 	"cmp"
+	"errors"
+	"fmt"
 	"slices"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
-// SortObjectsByHierarchy ensures that objects are sorted in the following order:
-//
-// 1. CRDs
-// 2. APIExports
-// 3. APIBindings
-// 4. Namespaces
-// 5. <everything else>
-//
-// This ensures they can be successfully applied in order (though some delay
-// might be required between creating a CRD and creating objects using that CRD).
-func SortObjectsByHierarchy(objects []*unstructured.Unstructured) {
-	slices.SortFunc(objects, func(objA, objB *unstructured.Unstructured) int {
-		weightA := objectWeight(objA)
-		weightB := objectWeight(objB)
+// OtherKinds is the placeholder in a kind order that marks the position of all
+// kinds that are not explicitly listed.
+const OtherKinds = "*"
 
-		return cmp.Compare(weightA, weightB)
-	})
-}
-
-var weights = []string{
+// DefaultKindOrder is the built-in priority table used to sort objects. Each
+// entry is a lowercase "kind.group" (just "kind" for the core group).
+var DefaultKindOrder = []string{
 	"customresourcedefinition.apiextensions.k8s.io",
+	"apiresourceschema.apis.kcp.io",
 	"apiexport.apis.kcp.io",
 	"apibinding.apis.kcp.io",
 	"namespace",
+	"serviceaccount",
+	"clusterrole.rbac.authorization.k8s.io",
+	"role.rbac.authorization.k8s.io",
+	"clusterrolebinding.rbac.authorization.k8s.io",
+	"rolebinding.rbac.authorization.k8s.io",
+	"configmap",
+	"secret",
+	"service",
+	OtherKinds,
+	"mutatingwebhookconfiguration.admissionregistration.k8s.io",
+	"validatingwebhookconfiguration.admissionregistration.k8s.io",
+	"workspace.tenancy.kcp.io",
+}
+
+// Sorter sorts objects based on a priority table of kinds and the references
+// between the objects.
+type Sorter struct {
+	weights     map[string]int
+	otherWeight int
+}
+
+// NewSorter returns a sorter for the given kind order. Kinds are matched
+// case-insensitively. If the order does not contain the OtherKinds placeholder,
+// unlisted kinds are sorted last.
+func NewSorter(order []string) (*Sorter, error) {
+	s := &Sorter{
+		weights:     map[string]int{},
+		otherWeight: -1,
+	}
+
+	for idx, kind := range order {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+
+		switch {
+		case kind == "":
+			return nil, errors.New("kind order must not contain empty entries")
+
+		case kind == OtherKinds:
+			if s.otherWeight >= 0 {
+				return nil, fmt.Errorf("kind order must not contain %q more than once", OtherKinds)
+			}
+			s.otherWeight = idx
+
+		default:
+			if _, exists := s.weights[kind]; exists {
+				return nil, fmt.Errorf("kind order must not contain %q more than once", kind)
+			}
+			s.weights[kind] = idx
+		}
+	}
+
+	if s.otherWeight < 0 {
+		s.otherWeight = len(order)
+	}
+
+	return s, nil
+}
+
+// DefaultSorter returns a sorter using the DefaultKindOrder.
+func DefaultSorter() *Sorter {
+	s, err := NewSorter(DefaultKindOrder)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default kind order: %v", err))
+	}
+
+	return s
+}
+
+// SortObjectsByHierarchy sorts the objects using the DefaultKindOrder. See
+// Sorter.Sort for more information.
+func SortObjectsByHierarchy(objects []*unstructured.Unstructured) {
+	DefaultSorter().Sort(objects)
 }
 
-func objectWeight(obj *unstructured.Unstructured) int {
-	gk := strings.ToLower(obj.GroupVersionKind().GroupKind().String())
+// Sort sorts the objects in-place, first by the weight of their kinds and then
+// by the references between them (e.g. a RoleBinding is always placed after
+// the Role it references, regardless of the kind order). The sort is stable,
+// so objects that have no ordering constraints between them keep their
+// relative order, making the result deterministic.
+//
+// This ensures they can be successfully applied in order (though some delay
+// might be required between creating a CRD and creating objects using that CRD).
+func (s *Sorter) Sort(objects []*unstructured.Unstructured) {
+	slices.SortStableFunc(objects, func(objA, objB *unstructured.Unstructured) int {
+		return cmp.Compare(s.objectWeight(objA), s.objectWeight(objB))
+	})
 
-	weight := slices.Index(weights, gk)
-	if weight == -1 {
-		weight = len(weights)
+	sortByReferences(objects)
+}
+
+func (s *Sorter) objectWeight(obj *unstructured.Unstructured) int {
+	weight, exists := s.weights[groupKindKey(obj)]
+	if !exists {
+		weight = s.otherWeight
 	}
 
 	return weight
 }
+
+func groupKindKey(obj *unstructured.Unstructured) string {
+	return strings.ToLower(obj.GroupVersionKind().GroupKind().String())
+}
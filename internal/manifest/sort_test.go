@@ -30,6 +30,9 @@ func TestSortObjectsByHierarchy(t *testing.T) {
 	configMap := newUnstructured("v1", "ConfigMap", "test-cm")
 	deployment := newUnstructured("apps/v1", "Deployment", "test-deploy")
 	service := newUnstructured("v1", "Service", "test-svc")
+	statefulSet := newUnstructured("apps/v1", "StatefulSet", "test-sts")
+	webhook := newUnstructured("admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", "test-webhook")
+	workspace := newUnstructured("tenancy.kcp.io/v1alpha1", "Workspace", "test-ws")
 
 	testcases := []struct {
 		name     string
@@ -69,7 +72,17 @@ func TestSortObjectsByHierarchy(t *testing.T) {
 		{
 			name:     "only regular objects",
 			input:    []*unstructured.Unstructured{service, configMap, deployment},
-			expected: []*unstructured.Unstructured{service, configMap, deployment},
+			expected: []*unstructured.Unstructured{configMap, service, deployment},
+		},
+		{
+			name:     "unknown kinds keep their relative order",
+			input:    []*unstructured.Unstructured{deployment, statefulSet, configMap},
+			expected: []*unstructured.Unstructured{configMap, deployment, statefulSet},
+		},
+		{
+			name:     "workspaces and webhooks come last",
+			input:    []*unstructured.Unstructured{workspace, webhook, deployment, service},
+			expected: []*unstructured.Unstructured{service, deployment, webhook, workspace},
 		},
 	}
 
@@ -90,6 +103,145 @@ func TestSortObjectsByHierarchy(t *testing.T) {
 	}
 }
 
+func TestSorterWithCustomOrder(t *testing.T) {
+	configMap := newUnstructured("v1", "ConfigMap", "test-cm")
+	secret := newUnstructured("v1", "Secret", "test-secret")
+	deployment := newUnstructured("apps/v1", "Deployment", "test-deploy")
+
+	sorter, err := NewSorter([]string{"Secret", OtherKinds, "ConfigMap"})
+	if err != nil {
+		t.Fatalf("Failed to create sorter: %v", err)
+	}
+
+	objects := []*unstructured.Unstructured{configMap, deployment, secret}
+	sorter.Sort(objects)
+
+	expected := []*unstructured.Unstructured{secret, deployment, configMap}
+	for i, obj := range objects {
+		if obj != expected[i] {
+			t.Fatalf("At index %d: expected %s, got %s", i, expected[i].GetKind(), obj.GetKind())
+		}
+	}
+}
+
+func TestNewSorterValidation(t *testing.T) {
+	testcases := []struct {
+		name    string
+		order   []string
+		invalid bool
+	}{
+		{
+			name:  "default order",
+			order: DefaultKindOrder,
+		},
+		{
+			name:  "without placeholder",
+			order: []string{"namespace"},
+		},
+		{
+			name:    "duplicate kinds",
+			order:   []string{"namespace", "Namespace"},
+			invalid: true,
+		},
+		{
+			name:    "duplicate placeholder",
+			order:   []string{OtherKinds, "namespace", OtherKinds},
+			invalid: true,
+		},
+		{
+			name:    "empty entry",
+			order:   []string{"namespace", ""},
+			invalid: true,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSorter(tt.order)
+			if tt.invalid != (err != nil) {
+				t.Fatalf("Expected invalid=%v, but got error %v", tt.invalid, err)
+			}
+		})
+	}
+}
+
+func TestSortByReferences(t *testing.T) {
+	role := newUnstructured("rbac.authorization.k8s.io/v1", "Role", "reader")
+	role.SetNamespace("app")
+
+	binding := newUnstructured("rbac.authorization.k8s.io/v1", "RoleBinding", "reader")
+	binding.SetNamespace("app")
+	binding.Object["roleRef"] = map[string]any{
+		"apiGroup": "rbac.authorization.k8s.io",
+		"kind":     "Role",
+		"name":     "reader",
+	}
+
+	namespace := newUnstructured("v1", "Namespace", "app")
+
+	config := newUnstructured("v1", "ConfigMap", "app-config")
+	config.SetNamespace("app")
+
+	deployment := newUnstructured("apps/v1", "Deployment", "app")
+	deployment.SetNamespace("app")
+	deployment.Object["spec"] = map[string]any{
+		"template": map[string]any{
+			"spec": map[string]any{
+				"volumes": []any{
+					map[string]any{
+						"name":      "config",
+						"configMap": map[string]any{"name": "app-config"},
+					},
+				},
+			},
+		},
+	}
+
+	crd := newUnstructured("apiextensions.k8s.io/v1", "CustomResourceDefinition", "widgets.example.com")
+	crd.Object["spec"] = map[string]any{
+		"group": "example.com",
+		"names": map[string]any{"kind": "Widget"},
+	}
+
+	widget := newUnstructured("example.com/v1", "Widget", "my-widget")
+
+	// This order deliberately places all dependents before their dependencies.
+	sorter, err := NewSorter([]string{"Widget.example.com", "Deployment.apps", "RoleBinding.rbac.authorization.k8s.io", OtherKinds})
+	if err != nil {
+		t.Fatalf("Failed to create sorter: %v", err)
+	}
+
+	objects := []*unstructured.Unstructured{widget, binding, deployment, role, config, namespace, crd}
+	sorter.Sort(objects)
+
+	position := func(obj *unstructured.Unstructured) int {
+		for i, o := range objects {
+			if o == obj {
+				return i
+			}
+		}
+		t.Fatalf("Object %s is missing from sorted output.", obj.GetName())
+		return -1
+	}
+
+	dependencies := []struct {
+		dependent  *unstructured.Unstructured
+		dependency *unstructured.Unstructured
+	}{
+		{dependent: binding, dependency: role},
+		{dependent: binding, dependency: namespace},
+		{dependent: deployment, dependency: config},
+		{dependent: config, dependency: namespace},
+		{dependent: widget, dependency: crd},
+	}
+
+	for _, dep := range dependencies {
+		if position(dep.dependent) < position(dep.dependency) {
+			t.Errorf("Expected %s %q to come after %s %q.", dep.dependent.GetKind(), dep.dependent.GetName(), dep.dependency.GetKind(), dep.dependency.GetName())
+		}
+	}
+}
+
 func newUnstructured(apiVersion, kind, name string) *unstructured.Unstructured {
 	return &unstructured.Unstructured{
 		Object: map[string]any{
@@ -0,0 +1,262 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kcp-dev/init-agent/internal/tracing"
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/yaml"
+)
+
+// Transformer modifies the objects rendered by a source before they are
+// applied. A nil Transformer does not change anything.
+type Transformer struct {
+	namespace   string
+	labels      map[string]string
+	annotations map[string]string
+	namePrefix  string
+	nameSuffix  string
+	patches     []objectPatch
+}
+
+type objectPatch struct {
+	target *initializationv1alpha1.ObjectSelector
+	patch  jsonpatch.Patch
+}
+
+// NewTransformer combines the given transformations, usually those of an
+// InitTarget and those of one of its sources. Later transformations override
+// the namespace and name prefix/suffix of earlier ones, their labels and
+// annotations are merged and their patches are applied after those of the
+// earlier transformations. If no transformations are given, nil is returned.
+func NewTransformer(transformations ...*initializationv1alpha1.Transformations) (*Transformer, error) {
+	var t *Transformer
+
+	for _, tf := range transformations {
+		if tf == nil {
+			continue
+		}
+
+		if t == nil {
+			t = &Transformer{
+				labels:      map[string]string{},
+				annotations: map[string]string{},
+			}
+		}
+
+		if tf.Namespace != "" {
+			t.namespace = tf.Namespace
+		}
+		if tf.NamePrefix != "" {
+			t.namePrefix = tf.NamePrefix
+		}
+		if tf.NameSuffix != "" {
+			t.nameSuffix = tf.NameSuffix
+		}
+
+		maps.Copy(t.labels, tf.Labels)
+		maps.Copy(t.annotations, tf.Annotations)
+
+		for idx, p := range tf.Patches {
+			patch, err := decodePatch(p.Patch)
+			if err != nil {
+				return nil, fmt.Errorf("invalid patch #%d: %w", idx, err)
+			}
+
+			t.patches = append(t.patches, objectPatch{target: p.Target, patch: patch})
+		}
+	}
+
+	return t, nil
+}
+
+func decodePatch(patch string) (jsonpatch.Patch, error) {
+	// JSON is valid YAML, so this handles both
+	data, err := yaml.YAMLToJSON([]byte(patch))
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonpatch.DecodePatch(data)
+}
+
+// Transform modifies the given objects in place. The RESTMapper is used to
+// determine whether objects are namespaced; objects of kinds that are not yet
+// known (and whose CRD is not part of the objects) are not namespaced by the
+// transformer, the applier will wait for their kind to become available.
+func (t *Transformer) Transform(ctx context.Context, objs []*unstructured.Unstructured, mapper meta.RESTMapper) error {
+	if t == nil {
+		return nil
+	}
+
+	_, span := tracing.Start(ctx, "manifest.Transform", trace.WithAttributes(attribute.Int("objects", len(objs))))
+	defer span.End()
+
+	crds := crdScopes(objs)
+
+	for _, obj := range objs {
+		if err := t.transform(obj, mapper, crds); err != nil {
+			return tracing.RecordError(span, fmt.Errorf("failed to transform %s %q: %w", obj.GetKind(), obj.GetName(), err))
+		}
+	}
+
+	return nil
+}
+
+func (t *Transformer) transform(obj *unstructured.Unstructured, mapper meta.RESTMapper, crds map[string]bool) error {
+	if t.namespace != "" && obj.GetNamespace() == "" {
+		namespaced, err := isNamespaced(obj, mapper, crds)
+		if err != nil {
+			return err
+		}
+
+		if namespaced {
+			obj.SetNamespace(t.namespace)
+		}
+	}
+
+	if len(t.labels) > 0 {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		maps.Copy(labels, t.labels)
+		obj.SetLabels(labels)
+	}
+
+	if len(t.annotations) > 0 {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		maps.Copy(annotations, t.annotations)
+		obj.SetAnnotations(annotations)
+	}
+
+	for idx, p := range t.patches {
+		if !matchesSelector(obj, p.target) {
+			continue
+		}
+
+		if err := applyPatch(obj, p.patch); err != nil {
+			return fmt.Errorf("failed to apply patch #%d: %w", idx, err)
+		}
+	}
+
+	if (t.namePrefix != "" || t.nameSuffix != "") && renamable(obj) {
+		obj.SetName(t.namePrefix + obj.GetName() + t.nameSuffix)
+	}
+
+	return nil
+}
+
+// crdScopes maps the "kind.group" of every CRD in the given objects to
+// whether its resources are namespaced.
+func crdScopes(objects []*unstructured.Unstructured) map[string]bool {
+	scopes := map[string]bool{}
+
+	for _, obj := range objects {
+		if groupKindKey(obj) == "customresourcedefinition.apiextensions.k8s.io" {
+			group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+			scope, _, _ := unstructured.NestedString(obj.Object, "spec", "scope")
+			scopes[strings.ToLower(kind+"."+group)] = scope == "Namespaced"
+		}
+	}
+
+	return scopes
+}
+
+func isNamespaced(obj *unstructured.Unstructured, mapper meta.RESTMapper, crds map[string]bool) (bool, error) {
+	if namespaced, ok := crds[groupKindKey(obj)]; ok {
+		return namespaced, nil
+	}
+
+	gvk := obj.GroupVersionKind()
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to determine scope: %w", err)
+	}
+
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+func matchesSelector(obj *unstructured.Unstructured, selector *initializationv1alpha1.ObjectSelector) bool {
+	if selector == nil {
+		return true
+	}
+
+	gvk := obj.GroupVersionKind()
+
+	return (selector.Group == "" || selector.Group == gvk.Group) &&
+		(selector.Kind == "" || selector.Kind == gvk.Kind) &&
+		(selector.Name == "" || selector.Name == obj.GetName()) &&
+		(selector.Namespace == "" || selector.Namespace == obj.GetNamespace())
+}
+
+func applyPatch(obj *unstructured.Unstructured, patch jsonpatch.Patch) error {
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return err
+	}
+
+	patched, err := patch.Apply(data)
+	if err != nil {
+		return err
+	}
+
+	// decode numbers like the API machinery does, i.e. integers as int64
+	// instead of float64
+	result := map[string]any{}
+	if err := utiljson.Unmarshal(patched, &result); err != nil {
+		return err
+	}
+
+	obj.Object = result
+
+	return nil
+}
+
+// renamable returns false for objects whose names carry a meaning and must
+// therefore not be changed.
+func renamable(obj *unstructured.Unstructured) bool {
+	switch groupKindKey(obj) {
+	case "namespace", "customresourcedefinition.apiextensions.k8s.io":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"testing"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newTestMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+
+	return mapper
+}
+
+func newTestCRD(group, kind, scope string) *unstructured.Unstructured {
+	crd := newUnstructured("apiextensions.k8s.io/v1", "CustomResourceDefinition", "test-crd")
+	crd.Object["spec"] = map[string]any{
+		"group": group,
+		"scope": scope,
+		"names": map[string]any{
+			"kind": kind,
+		},
+	}
+
+	return crd
+}
+
+func TestTransformNamespace(t *testing.T) {
+	configMap := newUnstructured("v1", "ConfigMap", "cm")
+	explicit := newUnstructured("v1", "ConfigMap", "explicit")
+	explicit.SetNamespace("other")
+	namespace := newUnstructured("v1", "Namespace", "ns")
+	clusterRole := newUnstructured("rbac.authorization.k8s.io/v1", "ClusterRole", "role")
+	crd := newTestCRD("example.com", "Widget", "Namespaced")
+	widget := newUnstructured("example.com/v1", "Widget", "widget")
+	unknown := newUnstructured("unknown.com/v1", "Thing", "thing")
+
+	transformer, err := NewTransformer(&initializationv1alpha1.Transformations{Namespace: "default"})
+	if err != nil {
+		t.Fatalf("Failed to create transformer: %v", err)
+	}
+
+	objects := []*unstructured.Unstructured{configMap, explicit, namespace, clusterRole, crd, widget, unknown}
+	if err := transformer.Transform(context.Background(), objects, newTestMapper()); err != nil {
+		t.Fatalf("Failed to transform objects: %v", err)
+	}
+
+	expected := map[*unstructured.Unstructured]string{
+		configMap:   "default",
+		explicit:    "other",
+		namespace:   "",
+		clusterRole: "",
+		crd:         "",
+		widget:      "default",
+		unknown:     "",
+	}
+
+	for obj, namespace := range expected {
+		if obj.GetNamespace() != namespace {
+			t.Errorf("Expected %s %q to have namespace %q, but got %q.", obj.GetKind(), obj.GetName(), namespace, obj.GetNamespace())
+		}
+	}
+}
+
+func TestTransformMetadata(t *testing.T) {
+	configMap := newUnstructured("v1", "ConfigMap", "cm")
+	configMap.SetLabels(map[string]string{"app": "mine", "team": "b"})
+	namespace := newUnstructured("v1", "Namespace", "ns")
+
+	targetTransformations := &initializationv1alpha1.Transformations{
+		Labels:      map[string]string{"team": "a", "tier": "gold"},
+		Annotations: map[string]string{"note": "target"},
+		NamePrefix:  "target-",
+		NameSuffix:  "-x",
+	}
+
+	sourceTransformations := &initializationv1alpha1.Transformations{
+		Labels:     map[string]string{"tier": "silver"},
+		NamePrefix: "source-",
+	}
+
+	transformer, err := NewTransformer(targetTransformations, sourceTransformations)
+	if err != nil {
+		t.Fatalf("Failed to create transformer: %v", err)
+	}
+
+	if err := transformer.Transform(context.Background(), []*unstructured.Unstructured{configMap, namespace}, newTestMapper()); err != nil {
+		t.Fatalf("Failed to transform objects: %v", err)
+	}
+
+	if name := configMap.GetName(); name != "source-cm-x" {
+		t.Errorf("Expected ConfigMap to be renamed to source-cm-x, but got %q.", name)
+	}
+
+	if name := namespace.GetName(); name != "ns" {
+		t.Errorf("Expected Namespace not to be renamed, but got %q.", name)
+	}
+
+	expectedLabels := map[string]string{"app": "mine", "team": "a", "tier": "silver"}
+	if labels := configMap.GetLabels(); !equality.Semantic.DeepEqual(labels, expectedLabels) {
+		t.Errorf("Expected labels %v, but got %v.", expectedLabels, labels)
+	}
+
+	expectedAnnotations := map[string]string{"note": "target"}
+	if annotations := namespace.GetAnnotations(); !equality.Semantic.DeepEqual(annotations, expectedAnnotations) {
+		t.Errorf("Expected annotations %v, but got %v.", expectedAnnotations, annotations)
+	}
+}
+
+func TestTransformPatches(t *testing.T) {
+	configMap := newUnstructured("v1", "ConfigMap", "cm")
+	configMap.Object["data"] = map[string]any{"foo": "bar"}
+	other := newUnstructured("v1", "ConfigMap", "other")
+	other.Object["data"] = map[string]any{"foo": "bar"}
+
+	transformer, err := NewTransformer(&initializationv1alpha1.Transformations{
+		NamePrefix: "prefixed-",
+		Patches: []initializationv1alpha1.ObjectPatch{
+			{
+				Target: &initializationv1alpha1.ObjectSelector{Kind: "ConfigMap", Name: "cm"},
+				Patch: `
+- op: replace
+  path: /data/foo
+  value: baz`,
+			},
+			{
+				Patch: `[{"op": "add", "path": "/data/all", "value": "yes"}]`,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create transformer: %v", err)
+	}
+
+	if err := transformer.Transform(context.Background(), []*unstructured.Unstructured{configMap, other}, newTestMapper()); err != nil {
+		t.Fatalf("Failed to transform objects: %v", err)
+	}
+
+	expected := map[*unstructured.Unstructured]map[string]any{
+		configMap: {"foo": "baz", "all": "yes"},
+		other:     {"foo": "bar", "all": "yes"},
+	}
+
+	for obj, data := range expected {
+		if !equality.Semantic.DeepEqual(obj.Object["data"], data) {
+			t.Errorf("Expected %q to have data %v, but got %v.", obj.GetName(), data, obj.Object["data"])
+		}
+	}
+
+	if name := configMap.GetName(); name != "prefixed-cm" {
+		t.Errorf("Expected patches to select objects before they are renamed, but got %q.", name)
+	}
+}
+
+func TestTransformPatchesKeepIntegers(t *testing.T) {
+	objects, err := ParseYAML([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment
+spec:
+  replicas: 1
+  progressDeadlineSeconds: 600
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+
+	transformer, err := NewTransformer(&initializationv1alpha1.Transformations{
+		Patches: []initializationv1alpha1.ObjectPatch{{
+			Patch: `[{"op": "replace", "path": "/spec/replicas", "value": 3}, {"op": "add", "path": "/spec/ratio", "value": 0.5}]`,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create transformer: %v", err)
+	}
+
+	if err := transformer.Transform(context.Background(), objects, newTestMapper()); err != nil {
+		t.Fatalf("Failed to transform objects: %v", err)
+	}
+
+	spec := objects[0].Object["spec"].(map[string]any)
+
+	expected := map[string]any{
+		"replicas":                int64(3),
+		"progressDeadlineSeconds": int64(600),
+		"ratio":                   0.5,
+	}
+
+	for field, value := range expected {
+		if spec[field] != value {
+			t.Errorf("Expected %s to be %v (%T), got %v (%T).", field, value, value, spec[field], spec[field])
+		}
+	}
+
+	// the patched object must still be usable with the unstructured helpers
+	if replicas, found, err := unstructured.NestedInt64(objects[0].Object, "spec", "replicas"); err != nil || !found || replicas != 3 {
+		t.Errorf("Expected spec.replicas to be readable as int64, got %d (found: %v, error: %v).", replicas, found, err)
+	}
+}
+
+func TestTransformInvalidPatches(t *testing.T) {
+	if _, err := NewTransformer(&initializationv1alpha1.Transformations{
+		Patches: []initializationv1alpha1.ObjectPatch{{Patch: `{"op": "add"}`}},
+	}); err == nil {
+		t.Fatal("Expected an error for a patch that is not a list of operations.")
+	}
+
+	transformer, err := NewTransformer(&initializationv1alpha1.Transformations{
+		Patches: []initializationv1alpha1.ObjectPatch{{Patch: `[{"op": "remove", "path": "/data/missing"}]`}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create transformer: %v", err)
+	}
+
+	objects := []*unstructured.Unstructured{newUnstructured("v1", "ConfigMap", "cm")}
+	if err := transformer.Transform(context.Background(), objects, newTestMapper()); err == nil {
+		t.Fatal("Expected an error when a patch cannot be applied.")
+	}
+}
+
+func TestNilTransformer(t *testing.T) {
+	transformer, err := NewTransformer(nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create transformer: %v", err)
+	}
+
+	if transformer != nil {
+		t.Fatal("Expected no transformer without any transformations.")
+	}
+
+	if err := transformer.Transform(context.Background(), []*unstructured.Unstructured{newUnstructured("v1", "ConfigMap", "cm")}, nil); err != nil {
+		t.Fatalf("Expected nil transformer to do nothing, but got: %v", err)
+	}
+}
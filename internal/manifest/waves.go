@@ -0,0 +1,83 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Wave is a group of objects that are applied together, before any object of
+// a later wave is applied.
+type Wave struct {
+	Index   int
+	Objects []*unstructured.Unstructured
+}
+
+// GroupObjectsByWave groups the given objects into waves, based on their
+// wave annotation. The resulting waves are sorted in ascending order and the
+// objects within each wave are sorted using the given sorter.
+func GroupObjectsByWave(objects []*unstructured.Unstructured, sorter *Sorter) ([]Wave, error) {
+	groups := map[int][]*unstructured.Unstructured{}
+
+	for _, obj := range objects {
+		wave, err := objectWave(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		groups[wave] = append(groups[wave], obj)
+	}
+
+	waves := make([]Wave, 0, len(groups))
+	for _, idx := range slices.Sorted(maps.Keys(groups)) {
+		objs := groups[idx]
+		sorter.Sort(objs)
+
+		waves = append(waves, Wave{
+			Index:   idx,
+			Objects: objs,
+		})
+	}
+
+	return waves, nil
+}
+
+func objectWave(obj *unstructured.Unstructured) (int, error) {
+	value, exists := obj.GetAnnotations()[initializationv1alpha1.WaveAnnotation]
+	if !exists {
+		return 0, nil
+	}
+
+	wave, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation %q on %s %q: must be an integer", initializationv1alpha1.WaveAnnotation, value, obj.GetKind(), obj.GetName())
+	}
+
+	return wave, nil
+}
+
+func waitForReady(obj *unstructured.Unstructured) bool {
+	return obj.GetAnnotations()[initializationv1alpha1.WaitForReadyAnnotation] == "true"
+}
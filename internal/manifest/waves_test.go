@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"testing"
+
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGroupObjectsByWave(t *testing.T) {
+	crd := newUnstructured("apiextensions.k8s.io/v1", "CustomResourceDefinition", "test-crd")
+	namespace := newUnstructured("v1", "Namespace", "test-ns")
+	configMap := newUnstructured("v1", "ConfigMap", "test-cm")
+	lateNamespace := withWave(newUnstructured("v1", "Namespace", "late-ns"), "3")
+	lateConfigMap := withWave(newUnstructured("v1", "ConfigMap", "late-cm"), "3")
+	earlyConfigMap := withWave(newUnstructured("v1", "ConfigMap", "early-cm"), "-1")
+
+	testcases := []struct {
+		name     string
+		input    []*unstructured.Unstructured
+		expected [][]*unstructured.Unstructured
+		invalid  bool
+	}{
+		{
+			name:     "empty input",
+			input:    []*unstructured.Unstructured{},
+			expected: [][]*unstructured.Unstructured{},
+		},
+		{
+			name:     "no annotations yields a single wave",
+			input:    []*unstructured.Unstructured{configMap, namespace, crd},
+			expected: [][]*unstructured.Unstructured{{crd, namespace, configMap}},
+		},
+		{
+			name:  "waves are sorted and hierarchy applies within each wave",
+			input: []*unstructured.Unstructured{lateConfigMap, configMap, lateNamespace, earlyConfigMap, crd},
+			expected: [][]*unstructured.Unstructured{
+				{earlyConfigMap},
+				{crd, configMap},
+				{lateNamespace, lateConfigMap},
+			},
+		},
+		{
+			name:    "invalid wave",
+			input:   []*unstructured.Unstructured{withWave(newUnstructured("v1", "ConfigMap", "broken"), "first")},
+			invalid: true,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			waves, err := GroupObjectsByWave(tt.input, DefaultSorter())
+			if tt.invalid {
+				if err == nil {
+					t.Fatal("Expected error, but got none.")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(waves) != len(tt.expected) {
+				t.Fatalf("Expected %d waves, got %d", len(tt.expected), len(waves))
+			}
+
+			for i, wave := range waves {
+				if len(wave.Objects) != len(tt.expected[i]) {
+					t.Fatalf("Wave %d: expected %d objects, got %d", i, len(tt.expected[i]), len(wave.Objects))
+				}
+
+				for j, obj := range wave.Objects {
+					if obj != tt.expected[i][j] {
+						t.Fatalf("Wave %d, index %d: expected %s, got %s", i, j, tt.expected[i][j].GetName(), obj.GetName())
+					}
+				}
+			}
+		})
+	}
+}
+
+func withWave(obj *unstructured.Unstructured, wave string) *unstructured.Unstructured {
+	obj.SetAnnotations(map[string]string{
+		initializationv1alpha1.WaveAnnotation: wave,
+	})
+
+	return obj
+}
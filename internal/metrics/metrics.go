@@ -0,0 +1,173 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics contains the Prometheus metrics of the init-agent. All
+// metrics are registered with controller-runtime's registry and are therefore
+// exposed on the manager's metrics endpoint. To keep the cardinality bounded,
+// no metric uses per-workspace labels.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace = "initagent"
+
+	// InitTargetLabel identifies an InitTarget as "<workspace>/<name>", since
+	// names alone are not unique across config and tenant workspaces.
+	InitTargetLabel  = "init_target"
+	InitializerLabel = "initializer"
+	SourceLabel      = "source"
+	GroupLabel       = "group"
+	VersionLabel     = "version"
+	KindLabel        = "kind"
+	StatusLabel      = "status"
+)
+
+var (
+	// WorkspacesInitialized counts the workspaces that have been initialized
+	// successfully. Workspaces that were only partially initialized (see
+	// FailurePolicyRemoveInitializer) are not included.
+	WorkspacesInitialized = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "workspaces_initialized_total",
+		Help:      "Number of workspaces that have been initialized successfully.",
+	}, []string{InitTargetLabel})
+
+	// InitializationFailures counts the workspaces whose retry policy was
+	// exhausted, i.e. to which the failure policy was applied. Every workspace
+	// is counted once.
+	InitializationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "initialization_failures_total",
+		Help:      "Number of workspaces whose initialization failed and to which the failure policy was applied.",
+	}, []string{InitTargetLabel})
+
+	// FailedAttempts counts failed initialization attempts.
+	FailedAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "initialization_attempts_failed_total",
+		Help:      "Number of failed workspace initialization attempts.",
+	}, []string{InitTargetLabel})
+
+	// InitializationDuration observes the time from the creation of a
+	// LogicalCluster until it was initialized successfully.
+	InitializationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "initialization_duration_seconds",
+		Help:      "Time from the creation of a LogicalCluster until it was initialized successfully.",
+		// 1s to ~2h
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{InitTargetLabel})
+
+	// SourceRenderDuration observes how long it took to render an init source.
+	SourceRenderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "source_render_duration_seconds",
+		Help:      "Time it took to render the manifests of an init source.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{InitTargetLabel, SourceLabel})
+
+	// SourceApplyDuration observes how long it took to apply an init source.
+	SourceApplyDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "source_apply_duration_seconds",
+		Help:      "Time it took to apply the manifests of an init source.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{InitTargetLabel, SourceLabel})
+
+	// PendingWorkspaces is the number of workspaces currently waiting for
+	// an initializer to be removed by this agent.
+	PendingWorkspaces = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workspaces_pending",
+		Help:      "Number of workspaces that are currently pending initialization.",
+	}, []string{InitializerLabel})
+
+	// InitializationsInFlight is the number of workspaces that are currently
+	// being rendered and applied, across all init controllers.
+	InitializationsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "initializations_in_flight",
+		Help:      "Number of workspace initializations that are currently running.",
+	})
+
+	// RunningManagers is the number of per-InitTarget managers that are
+	// currently running.
+	RunningManagers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "init_managers_running",
+		Help:      "Number of per-InitTarget multicluster managers that are currently running.",
+	})
+
+	// ManagerRestarts counts how often a failed per-InitTarget manager was
+	// restarted.
+	ManagerRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "init_manager_restarts_total",
+		Help:      "Number of times a failed per-InitTarget multicluster manager was restarted.",
+	}, []string{InitTargetLabel})
+
+	// InitTargetSuspended is 1 for suspended InitTargets and 0 otherwise.
+	InitTargetSuspended = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "init_target_suspended",
+		Help:      "Whether an InitTarget is suspended (1) or not (0).",
+	}, []string{InitTargetLabel})
+
+	// WorkspaceTypeInformers is the number of shared WorkspaceType informers,
+	// i.e. the number of distinct workspaces containing WorkspaceTypes that
+	// init controllers are running for.
+	WorkspaceTypeInformers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workspacetype_informers",
+		Help:      "Number of shared WorkspaceType informers, one per workspace containing WorkspaceTypes with running init controllers.",
+	})
+
+	// ObjectsApplied counts the objects that were created or already existed.
+	ObjectsApplied = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "objects_applied_total",
+		Help:      "Number of objects applied in workspaces, partitioned by whether they were created or already existed.",
+	}, []string{GroupLabel, VersionLabel, KindLabel, StatusLabel})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		WorkspacesInitialized,
+		InitializationFailures,
+		FailedAttempts,
+		InitializationDuration,
+		SourceRenderDuration,
+		SourceApplyDuration,
+		PendingWorkspaces,
+		InitializationsInFlight,
+		RunningManagers,
+		ManagerRestarts,
+		InitTargetSuspended,
+		WorkspaceTypeInformers,
+		ObjectsApplied,
+	)
+}
+
+// ObjectApplied records a single applied object.
+func ObjectApplied(gvk schema.GroupVersionKind, status string) {
+	ObjectsApplied.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, status).Inc()
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package settings
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Settings are the reloadable settings of the init-agent.
+type Settings struct {
+	// InitTargetSelector restricts the agent to the matching InitTargets.
+	InitTargetSelector labels.Selector
+
+	// InitWorkers is the default number of workspaces per InitTarget that are
+	// initialized in parallel.
+	InitWorkers int
+
+	// RetryInitialDelay and RetryMaxDelay are the default retry delays for
+	// InitTargets that do not configure them in their retry policy.
+	RetryInitialDelay time.Duration
+	RetryMaxDelay     time.Duration
+}
+
+// Store holds the current settings. A Store is safe for concurrent use.
+type Store struct {
+	lock        sync.RWMutex
+	current     Settings
+	subscribers map[chan struct{}]struct{}
+}
+
+// NewStore returns a store with the given initial settings.
+func NewStore(initial Settings) *Store {
+	return &Store{
+		current:     initial,
+		subscribers: map[chan struct{}]struct{}{},
+	}
+}
+
+// Get returns the current settings. A nil Store returns empty settings.
+func (s *Store) Get() Settings {
+	if s == nil {
+		return Settings{}
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.current
+}
+
+// Set replaces the current settings and notifies all subscribers.
+func (s *Store) Set(updated Settings) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.current = updated
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel that receives a value whenever the settings
+// change. The returned function must be called to unsubscribe.
+func (s *Store) Subscribe() (<-chan struct{}, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ch := make(chan struct{}, 1)
+	s.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		delete(s.subscribers, ch)
+	}
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// GroupLabel is placed on all member Leases to find the other members of
+	// the same shard group.
+	GroupLabel = "initialization.kcp.io/shard-group"
+)
+
+type Options struct {
+	// Namespace is the namespace in the config workspace where the member
+	// Leases are created.
+	Namespace string
+
+	// Group is the name of the shard group. All replicas of the same agent
+	// must use the same group.
+	Group string
+
+	// Identity is the unique name of this replica (e.g. the pod name).
+	Identity string
+
+	// LeaseDuration is the duration after which a member that failed to renew
+	// its Lease is considered gone. Leases are renewed every third of this.
+	LeaseDuration time.Duration
+}
+
+// Membership keeps track of all replicas of a shard group, based on one Lease
+// per replica in the config workspace, and decides which replica is
+// responsible for which logical cluster.
+//
+// There is no fencing between replicas: every replica only learns about a
+// change of the members when it syncs its membership, which happens every
+// third of the LeaseDuration. To keep two replicas from processing the same
+// logical cluster at the same time, a replica that takes over a cluster from
+// another replica waits for one such interval before it considers itself
+// responsible. The previous owner has noticed the change by then, but an
+// initialization that it had already started still finishes. This overlap is
+// harmless, since applying objects and removing the initializer are both
+// idempotent.
+type Membership struct {
+	client ctrlruntimeclient.Client
+	reader ctrlruntimeclient.Reader
+	log    *zap.SugaredLogger
+	opts   Options
+	now    func() time.Time
+	// afterFunc calls f once d has elapsed.
+	afterFunc func(d time.Duration, f func())
+
+	lock        sync.RWMutex
+	members     []string
+	subscribers map[chan struct{}]struct{}
+
+	// previous are the members before the latest change, whose clusters are
+	// not taken over before handoverUntil.
+	previous      []string
+	handoverUntil time.Time
+}
+
+// NewMembership returns a new membership. Writes go through the client, while
+// the reader is used to list Leases (usually an uncached reader).
+func NewMembership(client ctrlruntimeclient.Client, reader ctrlruntimeclient.Reader, log *zap.SugaredLogger, opts Options) *Membership {
+	return &Membership{
+		client:      client,
+		reader:      reader,
+		log:         log.Named("sharding").With("identity", opts.Identity),
+		opts:        opts,
+		now:         time.Now,
+		afterFunc:   func(d time.Duration, f func()) { time.AfterFunc(d, f) },
+		subscribers: map[chan struct{}]struct{}{},
+	}
+}
+
+// Owns returns true if this replica is responsible for the given logical
+// cluster. Before the membership has been synced for the first time, this
+// returns false for all clusters. Clusters that were owned by another replica
+// before the latest change of the members are only owned after the handover
+// interval.
+func (m *Membership) Owns(clusterName string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if Owner(m.members, clusterName) != m.opts.Identity {
+		return false
+	}
+
+	if m.now().Before(m.handoverUntil) {
+		if previous := Owner(m.previous, clusterName); previous != "" && previous != m.opts.Identity {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Members returns the identities of all currently known members.
+func (m *Membership) Members() []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return slices.Clone(m.members)
+}
+
+// Subscribe returns a channel that receives a value whenever the members of
+// the shard group change. The returned function must be called to unsubscribe.
+func (m *Membership) Subscribe() (<-chan struct{}, func()) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	ch := make(chan struct{}, 1)
+	m.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+
+		delete(m.subscribers, ch)
+	}
+}
+
+// Start keeps this replica's Lease alive and periodically updates the list of
+// members until the context is cancelled. The Lease is deleted on shutdown so
+// that other replicas can take over immediately.
+func (m *Membership) Start(ctx context.Context) error {
+	interval := m.syncInterval()
+
+	for {
+		if err := m.sync(ctx); err != nil {
+			m.log.Warnw("Failed to sync shard membership", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			m.leave()
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; every replica
+// must take part in the shard group.
+func (m *Membership) NeedLeaderElection() bool {
+	return false
+}
+
+func (m *Membership) sync(ctx context.Context) error {
+	if err := m.renew(ctx); err != nil {
+		return fmt.Errorf("failed to renew Lease: %w", err)
+	}
+
+	leases := &coordinationv1.LeaseList{}
+	if err := m.reader.List(ctx, leases, ctrlruntimeclient.InNamespace(m.opts.Namespace), ctrlruntimeclient.MatchingLabels{GroupLabel: m.opts.Group}); err != nil {
+		return fmt.Errorf("failed to list Leases: %w", err)
+	}
+
+	now := m.now()
+	members := []string{}
+
+	for _, lease := range leases.Items {
+		if isAlive(&lease, now) && lease.Spec.HolderIdentity != nil {
+			members = append(members, *lease.Spec.HolderIdentity)
+		}
+	}
+
+	// make sure this replica always considers itself, even if the List was served
+	// from a stale cache
+	if !slices.Contains(members, m.opts.Identity) {
+		members = append(members, m.opts.Identity)
+	}
+
+	slices.Sort(members)
+	m.setMembers(members)
+
+	return nil
+}
+
+func (m *Membership) setMembers(members []string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if slices.Equal(m.members, members) {
+		return
+	}
+
+	m.log.Infow("Shard group members changed, rebalancing", "members", members)
+
+	// When joining, the clusters were owned by the other members before.
+	previous := m.members
+	if previous == nil {
+		previous = slices.DeleteFunc(slices.Clone(members), func(member string) bool {
+			return member == m.opts.Identity
+		})
+	}
+
+	handover := m.syncInterval()
+
+	m.previous = previous
+	m.handoverUntil = m.now().Add(handover)
+	m.members = members
+	m.notifyLocked()
+
+	// notify again once the clusters taken over from other members are owned
+	m.afterFunc(handover, func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+
+		m.notifyLocked()
+	})
+}
+
+func (m *Membership) notifyLocked() {
+	for ch := range m.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// syncInterval is how often the Lease is renewed and the members are updated.
+func (m *Membership) syncInterval() time.Duration {
+	return m.opts.LeaseDuration / 3
+}
+
+func (m *Membership) renew(ctx context.Context) error {
+	now := metav1.NewMicroTime(m.now())
+
+	lease := &coordinationv1.Lease{}
+	err := m.reader.Get(ctx, types.NamespacedName{Namespace: m.opts.Namespace, Name: m.leaseName()}, lease)
+	if ctrlruntimeclient.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	if err != nil {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: m.opts.Namespace,
+				Name:      m.leaseName(),
+				Labels:    map[string]string{GroupLabel: m.opts.Group},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To(m.opts.Identity),
+				LeaseDurationSeconds: ptr.To(int32(m.opts.LeaseDuration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+
+		return m.client.Create(ctx, lease)
+	}
+
+	lease.Spec.RenewTime = &now
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(m.opts.LeaseDuration.Seconds()))
+
+	return m.client.Update(ctx, lease)
+}
+
+func (m *Membership) leave() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: m.opts.Namespace,
+			Name:      m.leaseName(),
+		},
+	}
+
+	if err := m.client.Delete(ctx, lease); ctrlruntimeclient.IgnoreNotFound(err) != nil {
+		m.log.Warnw("Failed to delete Lease", zap.Error(err))
+	}
+}
+
+func (m *Membership) leaseName() string {
+	return fmt.Sprintf("%s-%s", m.opts.Group, m.opts.Identity)
+}
+
+func isAlive(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return false
+	}
+
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+
+	return now.Before(expiry)
+}
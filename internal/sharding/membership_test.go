@@ -0,0 +1,289 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testLeaseDuration = 30 * time.Second
+
+// fakeClock is shared by all members of a test.
+type fakeClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	f  func()
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+// Step advances the clock and calls all functions that are due.
+func (c *fakeClock) Step(d time.Duration) {
+	c.lock.Lock()
+	c.now = c.now.Add(d)
+
+	var due []func()
+	c.timers = slices.DeleteFunc(c.timers, func(timer fakeTimer) bool {
+		if c.now.Before(timer.at) {
+			return false
+		}
+
+		due = append(due, timer.f)
+		return true
+	})
+	c.lock.Unlock()
+
+	for _, f := range due {
+		f()
+	}
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), f: f})
+}
+
+func newFakeLeaseClient(t *testing.T) ctrlruntimeclient.Client {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := coordinationv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed to build scheme: %v", err)
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).Build()
+}
+
+func newTestMembership(client ctrlruntimeclient.Client, clock *fakeClock, identity string) *Membership {
+	m := NewMembership(client, client, zap.NewNop().Sugar(), Options{
+		Namespace:     "default",
+		Group:         "agents",
+		Identity:      identity,
+		LeaseDuration: testLeaseDuration,
+	})
+	m.now = clock.Now
+	m.afterFunc = clock.AfterFunc
+
+	return m
+}
+
+func syncMembership(t *testing.T, m *Membership) {
+	t.Helper()
+
+	if err := m.sync(t.Context()); err != nil {
+		t.Fatalf("Failed to sync membership of %s: %v", m.opts.Identity, err)
+	}
+}
+
+func getLease(t *testing.T, client ctrlruntimeclient.Client, m *Membership) *coordinationv1.Lease {
+	t.Helper()
+
+	lease := &coordinationv1.Lease{}
+	if err := client.Get(t.Context(), types.NamespacedName{Namespace: "default", Name: m.leaseName()}, lease); err != nil {
+		t.Fatalf("Failed to get Lease of %s: %v", m.opts.Identity, err)
+	}
+
+	return lease
+}
+
+func expectMembers(t *testing.T, m *Membership, expected ...string) {
+	t.Helper()
+
+	if members := m.Members(); !slices.Equal(members, expected) {
+		t.Fatalf("Expected %s to see members %v, got %v.", m.opts.Identity, expected, members)
+	}
+}
+
+func TestMembershipLifecycle(t *testing.T) {
+	client := newFakeLeaseClient(t)
+	clock := &fakeClock{now: time.Now()}
+
+	a := newTestMembership(client, clock, "agent-a")
+	b := newTestMembership(client, clock, "agent-b")
+
+	if a.Owns("cluster") {
+		t.Fatal("Expected no cluster to be owned before the first sync.")
+	}
+
+	// joining creates a Lease
+	syncMembership(t, a)
+	expectMembers(t, a, "agent-a")
+
+	lease := getLease(t, client, a)
+	if lease.Labels[GroupLabel] != "agents" || lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "agent-a" {
+		t.Fatalf("Unexpected Lease %+v.", lease)
+	}
+
+	if !a.Owns("cluster") {
+		t.Fatal("Expected the only member to own all clusters.")
+	}
+
+	syncMembership(t, b)
+	expectMembers(t, b, "agent-a", "agent-b")
+
+	syncMembership(t, a)
+	expectMembers(t, a, "agent-a", "agent-b")
+
+	// renewing moves the renew time forward
+	clock.Step(a.syncInterval())
+	renewedBefore := getLease(t, client, a).Spec.RenewTime.Time
+
+	syncMembership(t, a)
+
+	if renewed := getLease(t, client, a).Spec.RenewTime.Time; !renewed.After(renewedBefore) {
+		t.Fatalf("Expected the Lease to be renewed after %v, but it was renewed at %v.", renewedBefore, renewed)
+	}
+
+	// agent-b stops renewing its Lease and is removed once it expired
+	clock.Step(testLeaseDuration - a.syncInterval() - time.Second)
+	syncMembership(t, a)
+	expectMembers(t, a, "agent-a", "agent-b")
+
+	clock.Step(time.Second)
+	syncMembership(t, a)
+	expectMembers(t, a, "agent-a")
+
+	// agent-b rejoins by renewing its Lease, and leaving deletes the Lease
+	syncMembership(t, b)
+	syncMembership(t, a)
+	expectMembers(t, a, "agent-a", "agent-b")
+
+	b.leave()
+
+	if err := client.Get(t.Context(), types.NamespacedName{Namespace: "default", Name: b.leaseName()}, &coordinationv1.Lease{}); err == nil {
+		t.Fatal("Expected the Lease to be deleted when leaving.")
+	}
+
+	syncMembership(t, a)
+	expectMembers(t, a, "agent-a")
+}
+
+func TestMembershipHandover(t *testing.T) {
+	client := newFakeLeaseClient(t)
+	clock := &fakeClock{now: time.Now()}
+
+	a := newTestMembership(client, clock, "agent-a")
+	b := newTestMembership(client, clock, "agent-b")
+
+	syncMembership(t, a)
+
+	// find clusters that move to agent-b once it joins
+	var moving []string
+	for i := range 100 {
+		if key := fmt.Sprintf("cluster-%d", i); Owner([]string{"agent-a", "agent-b"}, key) == "agent-b" {
+			moving = append(moving, key)
+		}
+	}
+
+	if len(moving) == 0 {
+		t.Fatal("Expected some clusters to move to the new member.")
+	}
+
+	changes, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+
+	syncMembership(t, b)
+	<-changes
+
+	for _, key := range moving {
+		// agent-a has not noticed agent-b yet and still processes the cluster
+		if !a.Owns(key) {
+			t.Fatalf("Expected agent-a to own %s until it syncs.", key)
+		}
+
+		// so agent-b must not start processing it yet
+		if b.Owns(key) {
+			t.Fatalf("Expected agent-b not to own %s during the handover.", key)
+		}
+	}
+
+	syncMembership(t, a)
+
+	for _, key := range moving {
+		if a.Owns(key) {
+			t.Fatalf("Expected agent-a to release %s after it noticed agent-b.", key)
+		}
+	}
+
+	// agent-a has synced by the end of the handover, so agent-b can take over
+	clock.Step(b.syncInterval())
+
+	for _, key := range moving {
+		if !b.Owns(key) {
+			t.Fatalf("Expected agent-b to own %s after the handover.", key)
+		}
+	}
+
+	// subscribers are notified again once the handover is over, so that the
+	// clusters are requeued
+	select {
+	case <-changes:
+	default:
+		t.Fatal("Expected a notification at the end of the handover.")
+	}
+
+	// clusters of a member that expired are taken over after the handover
+	// as well, since the member might still be running
+	var taken []string
+	for i := range 100 {
+		if key := fmt.Sprintf("cluster-%d", i); !slices.Contains(moving, key) {
+			taken = append(taken, key)
+		}
+	}
+
+	clock.Step(testLeaseDuration)
+	syncMembership(t, b)
+	expectMembers(t, b, "agent-b")
+
+	for _, key := range taken {
+		if b.Owns(key) {
+			t.Fatalf("Expected agent-b not to own %s during the handover.", key)
+		}
+	}
+
+	clock.Step(b.syncInterval())
+
+	for _, key := range taken {
+		if !b.Owns(key) {
+			t.Fatalf("Expected agent-b to own %s after the handover.", key)
+		}
+	}
+}
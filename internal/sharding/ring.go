@@ -0,0 +1,59 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"hash/fnv"
+)
+
+// Owner returns the member that owns the given key, using rendezvous (highest
+// random weight) hashing. When a member joins or leaves, only the keys owned
+// by that member move, all other keys keep their owner. An empty string is
+// returned if there are no members.
+func Owner(members []string, key string) string {
+	var (
+		owner string
+		best  uint64
+	)
+
+	for _, member := range members {
+		if score := weight(member, key); owner == "" || score > best || (score == best && member < owner) {
+			owner = member
+			best = score
+		}
+	}
+
+	return owner
+}
+
+func weight(member, key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(member))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+
+	// fnv does not spread similar inputs well enough, so mix the bits
+	// (finalizer of MurmurHash3)
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+
+	return x
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestOwner(t *testing.T) {
+	if owner := Owner(nil, "cluster"); owner != "" {
+		t.Fatalf("Expected no owner without members, got %q.", owner)
+	}
+
+	members := []string{"agent-a", "agent-b", "agent-c"}
+
+	keys := make([]string, 3000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("cluster-%d", i)
+	}
+
+	owners := map[string]string{}
+	counts := map[string]int{}
+	for _, key := range keys {
+		owner := Owner(members, key)
+		owners[key] = owner
+		counts[owner]++
+	}
+
+	// every member should receive a reasonable share
+	for _, member := range members {
+		if counts[member] < len(keys)/5 {
+			t.Errorf("Member %s only owns %d of %d keys.", member, counts[member], len(keys))
+		}
+	}
+
+	// when a member leaves, only its keys must move
+	remaining := []string{"agent-a", "agent-c"}
+	for _, key := range keys {
+		newOwner := Owner(remaining, key)
+		if owners[key] != "agent-b" && newOwner != owners[key] {
+			t.Fatalf("Key %s moved from %s to %s even though its owner did not leave.", key, owners[key], newOwner)
+		}
+	}
+}
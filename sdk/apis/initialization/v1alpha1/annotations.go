@@ -0,0 +1,104 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// WaveAnnotation can be placed on objects provided by an init source to group
+	// them into waves. Waves are applied in ascending numerical order and a wave
+	// is only applied once all objects of all previous waves have been applied
+	// (and are ready, see WaitForReadyAnnotation). Objects without this annotation
+	// belong to wave 0. Negative waves are allowed.
+	WaveAnnotation = "initialization.kcp.io/wave"
+
+	// WaitForReadyAnnotation can be set to "true" on an object to make the
+	// init-agent wait for the object to become ready before it proceeds with
+	// the next wave.
+	WaitForReadyAnnotation = "initialization.kcp.io/wait-for-ready"
+
+	// AttemptsAnnotation is placed by the init-agent on LogicalClusters to keep
+	// track of the number of failed initialization attempts. Its value is a JSON
+	// object mapping initializer names to the number of attempts.
+	AttemptsAnnotation = "initialization.kcp.io/attempts"
+
+	// PartiallyInitializedAnnotation is placed by the init-agent on
+	// LogicalClusters when an initializer was removed even though not all
+	// objects could be applied (see FailurePolicyRemoveInitializer). Its value
+	// is a comma-separated list of initializer names.
+	PartiallyInitializedAnnotation = "initialization.kcp.io/partially-initialized"
+
+	// FailedAnnotation is placed by the init-agent on LogicalClusters whose
+	// initialization has failed permanently (see FailurePolicyFail). Its value
+	// is a comma-separated list of initializer names.
+	FailedAnnotation = "initialization.kcp.io/failed"
+
+	// PriorityAnnotation can be placed on LogicalClusters to change the order
+	// in which they are initialized. Its value is an integer between -100 and
+	// 100 (defaults to 0) that is added to the InitTarget's priority;
+	// workspaces with higher priorities are initialized first.
+	PriorityAnnotation = "initialization.kcp.io/priority"
+
+	// TemplatesAnnotation can be placed on WorkspaceTypes in workspaces that
+	// the init-agent discovers. Its value is a comma-separated list of
+	// InitTemplate names (in the config workspace) and makes the init-agent
+	// manage an InitTarget for the WorkspaceType with these templates as its
+	// sources.
+	TemplatesAnnotation = "initialization.kcp.io/templates"
+
+	// DiscoveredFromAnnotation is placed on InitTargets managed by the
+	// init-agent's WorkspaceType discovery. Its value is the workspace path
+	// in which the WorkspaceType was discovered.
+	DiscoveredFromAnnotation = "initialization.kcp.io/discovered-from"
+
+	// DiscoveredLabel is set to "true" on all InitTargets that are managed by
+	// the init-agent's WorkspaceType discovery.
+	DiscoveredLabel = "initialization.kcp.io/discovered"
+
+	// ManagedByLabel is placed by the init-agent on all objects it creates
+	// (unless disabled). Its value defaults to ManagedByValue.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+
+	// ManagedByValue is the default value of the ManagedByLabel.
+	ManagedByValue = "kcp-init-agent"
+
+	// InitTargetLabel is placed by the init-agent on all objects it creates
+	// and contains the name of the InitTarget. It is omitted if the name is
+	// not a valid label value.
+	InitTargetLabel = "initialization.kcp.io/init-target"
+
+	// InitTargetAnnotation is placed by the init-agent on all objects it
+	// creates and contains the name of the InitTarget.
+	InitTargetAnnotation = "initialization.kcp.io/init-target"
+
+	// InitTargetClusterAnnotation is placed by the init-agent on all objects
+	// it creates and contains the logical cluster name of the InitTarget's
+	// workspace.
+	InitTargetClusterAnnotation = "initialization.kcp.io/init-target-cluster"
+
+	// SourceIndexAnnotation is placed by the init-agent on all objects it
+	// creates and contains the index of the init source in the InitTarget's
+	// list of sources.
+	SourceIndexAnnotation = "initialization.kcp.io/source-index"
+
+	// SourceTemplateAnnotation is placed by the init-agent on all objects it
+	// creates from an InitTemplate and contains the InitTemplate's name.
+	SourceTemplateAnnotation = "initialization.kcp.io/source-template"
+
+	// SourceVersionAnnotation is placed by the init-agent on all objects it
+	// creates and identifies the content of the source at the time the object
+	// was created. For InitTemplates, it is a hash of the template.
+	SourceVersionAnnotation = "initialization.kcp.io/source-version"
+)
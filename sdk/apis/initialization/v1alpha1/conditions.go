@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// InitControllerReadyCondition reflects the state of the init controller
+	// that the init-agent runs for an InitTarget.
+	InitControllerReadyCondition = "InitControllerReady"
+
+	// InitControllerStartingReason means the init controller is being started.
+	InitControllerStartingReason = "Starting"
+	// InitControllerRunningReason means the init controller is running.
+	InitControllerRunningReason = "Running"
+	// InitControllerFailedReason means the init controller has failed and
+	// will be restarted.
+	InitControllerFailedReason = "Failed"
+	// InitControllerConflictReason means no init controller is started
+	// because another InitTarget already handles the same initializer.
+	InitControllerConflictReason = "Conflict"
+	// InitControllerNoWorkspaceTypesReason means the InitTarget does not
+	// refer to any (existing) WorkspaceType.
+	InitControllerNoWorkspaceTypesReason = "NoWorkspaceTypes"
+	// InitControllerForbiddenReason means no init controller is started
+	// because the InitTarget may not use any of its WorkspaceTypes.
+	InitControllerForbiddenReason = "Forbidden"
+
+	// InitializationSucceededCondition reflects the outcome of the most recent
+	// initialization of a workspace that either completed or failed.
+	InitializationSucceededCondition = "InitializationSucceeded"
+
+	// InitializationSucceededReason means the most recent initialization
+	// completed.
+	InitializationSucceededReason = "Succeeded"
+	// InitializationFailedReason means the most recent initialization failed.
+	// The message names the workspace and the objects that could not be
+	// applied.
+	InitializationFailedReason = "Failed"
+
+	// SuspendedCondition is true while the InitTarget is suspended.
+	SuspendedCondition = "Suspended"
+
+	// SuspendedReason means the InitTarget's spec.suspend is set.
+	SuspendedReason = "Suspended"
+
+	// ConflictCondition is true if the InitTarget resolves to the same
+	// initializer as another, older InitTarget and is therefore ignored.
+	ConflictCondition = "Conflict"
+
+	// DuplicateInitializerReason means another InitTarget refers to the same
+	// WorkspaceType (and thereby the same initializer).
+	DuplicateInitializerReason = "DuplicateInitializer"
+
+	// ForbiddenCondition is true if a tenant's InitTarget refers to
+	// WorkspaceTypes outside of the tenant's own workspace, which are ignored.
+	ForbiddenCondition = "Forbidden"
+
+	// WorkspaceTypeNotOwnedReason means a WorkspaceType does not live in the
+	// same workspace as the tenant's InitTarget.
+	WorkspaceTypeNotOwnedReason = "WorkspaceTypeNotOwned"
+)
@@ -24,35 +24,267 @@ import (
 // +genclient:nonNamespaced
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="WSType Cluster",type="string",JSONPath=".spec.workspaceTypeRef.path"
 // +kubebuilder:printcolumn:name="WSType",type="string",JSONPath=".spec.workspaceTypeRef.name"
+// +kubebuilder:printcolumn:name="Suspended",type="boolean",JSONPath=".spec.suspend"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 type InitTarget struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec InitTargetSpec `json:"spec"`
+	Spec   InitTargetSpec   `json:"spec"`
+	Status InitTargetStatus `json:"status,omitempty"`
 }
 
+// +kubebuilder:validation:XValidation:rule="has(self.workspaceTypeRef) || (has(self.workspaceTypeRefs) && size(self.workspaceTypeRefs) > 0) || has(self.workspaceTypeSelector)",message="one of workspaceTypeRef, workspaceTypeRefs or workspaceTypeSelector must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.workspaceTypeRef) == has(oldSelf.workspaceTypeRef)",message="workspaceTypeRef cannot be added or removed"
 type InitTargetSpec struct {
-	WorkspaceTypeReference WorkspaceTypeReference `json:"workspaceTypeRef"`
-	Sources                []InitSource           `json:"sources"`
+	// WorkspaceTypeReference refers to a single WorkspaceType whose workspaces
+	// should be initialized. It can be combined with WorkspaceTypeReferences
+	// and WorkspaceTypeSelector; the InitTarget then handles the union of all
+	// referenced WorkspaceTypes. It cannot be changed once it has been set.
+	// A reference with an empty name is treated as unset.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="workspaceTypeRef is immutable"
+	// +optional
+	WorkspaceTypeReference WorkspaceTypeReference `json:"workspaceTypeRef,omitempty,omitzero"`
+
+	// WorkspaceTypeReferences refers to any number of WorkspaceTypes whose
+	// workspaces should be initialized.
+	// +optional
+	WorkspaceTypeReferences []WorkspaceTypeReference `json:"workspaceTypeRefs,omitempty"`
+
+	// WorkspaceTypeSelector selects WorkspaceTypes in a workspace by their
+	// labels. WorkspaceTypes that start or stop matching are picked up
+	// automatically.
+	// +optional
+	WorkspaceTypeSelector *WorkspaceTypeSelector `json:"workspaceTypeSelector,omitempty"`
+
+	// Sources provide the objects that are created in new workspaces.
+	// +kubebuilder:validation:MinItems=1
+	Sources []InitSource `json:"sources"`
+
+	// KindOrder optionally overrides the init-agent's default order in which
+	// objects of different kinds are applied. Each entry is a "kind.group" (or
+	// just "kind" for the core group), e.g. "role.rbac.authorization.k8s.io".
+	// The special entry "*" marks the position of all kinds not explicitly
+	// listed. References between objects (e.g. from a RoleBinding to its Role)
+	// are always respected, regardless of this order.
+	// +optional
+	KindOrder []string `json:"kindOrder,omitempty"`
+
+	// RetryPolicy configures how often and for how long the initialization of
+	// a workspace is retried when it fails.
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// FailurePolicy decides what happens to a workspace once its retry policy
+	// is exhausted. Defaults to "Block".
+	// +kubebuilder:default=Block
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+
+	// Suspend pauses the initialization of new workspaces. Workspaces remain
+	// blocked by the initializer until the InitTarget is resumed.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Workers is the number of workspaces of this InitTarget that are
+	// initialized in parallel. Defaults to the init-agent's --init-workers.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Workers *int32 `json:"workers,omitempty"`
+
+	// Priority determines the order in which workspaces of different
+	// InitTargets are initialized when the init-agent limits the number of
+	// concurrent initializations. Workspaces of InitTargets with a higher
+	// priority are initialized first. Individual workspaces can adjust their
+	// priority using the initialization.kcp.io/priority annotation.
+	// InitTargets in tenant workspaces cannot have a priority above 0, higher
+	// values are treated as 0.
+	// +kubebuilder:validation:Minimum=-100
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Transformations modify the objects of all sources before they are
+	// applied. Sources can override them with their own transformations.
+	// +optional
+	Transformations *Transformations `json:"transformations,omitempty"`
+}
+
+type RetryPolicy struct {
+	// InitialDelay is the delay before the first retry. The delay doubles with
+	// every failed attempt. Defaults to 5s.
+	// +optional
+	InitialDelay *metav1.Duration `json:"initialDelay,omitempty"`
+
+	// MaxDelay is the upper bound for the delay between two attempts. Defaults
+	// to 5m.
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+
+	// MaxAttempts is the number of failed attempts after which the failure
+	// policy is applied. If not set, the number of attempts is unlimited.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts *int32 `json:"maxAttempts,omitempty"`
+
+	// Deadline is the maximum duration, measured from the creation of the
+	// workspace, after which the failure policy is applied if the workspace
+	// has not been initialized yet. If not set, there is no deadline.
+	// +optional
+	Deadline *metav1.Duration `json:"deadline,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Block;RemoveInitializer;Fail
+type FailurePolicy string
+
+const (
+	// FailurePolicyBlock keeps the workspace blocked and continues retrying
+	// with the maximum delay.
+	FailurePolicyBlock FailurePolicy = "Block"
+	// FailurePolicyRemoveInitializer removes the initializer anyway, making
+	// the workspace usable, and annotates the LogicalCluster as partially
+	// initialized.
+	FailurePolicyRemoveInitializer FailurePolicy = "RemoveInitializer"
+	// FailurePolicyFail stops retrying and annotates the LogicalCluster as
+	// failed. The workspace remains blocked.
+	FailurePolicyFail FailurePolicy = "Fail"
+)
+
+type InitTargetStatus struct {
+	// Conditions describe the current state of the InitTarget, as observed by
+	// the init-agent.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ControllerRestarts is the number of times the init-agent had to restart
+	// the init controller for this InitTarget after it failed.
+	// +optional
+	ControllerRestarts int32 `json:"controllerRestarts,omitempty"`
 }
 
 type WorkspaceTypeReference struct {
+	// Path is the workspace path (or logical cluster name) of the
+	// WorkspaceType. An empty path refers to the InitTarget's workspace.
+	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(:[a-z0-9]([-a-z0-9]*[a-z0-9])?)*)?$`
 	Path string `json:"path"`
+
+	// Name is the name of the WorkspaceType.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 	Name string `json:"name"`
 }
 
+type WorkspaceTypeSelector struct {
+	// Path is the workspace path (or logical cluster name) in which the
+	// WorkspaceTypes are selected. Defaults to the InitTarget's workspace.
+	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(:[a-z0-9]([-a-z0-9]*[a-z0-9])?)*)?$`
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Selector is the label selector that WorkspaceTypes must match.
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// InitSource configures exactly one source of objects.
+// +kubebuilder:validation:XValidation:rule="has(self.template)",message="exactly one source must be configured"
 type InitSource struct {
+	// Template renders the objects from an InitTemplate.
+	// +optional
 	Template *TemplateInitSource `json:"template,omitempty"`
+
+	// Transformations modify the objects of this source before they are
+	// applied. They override the InitTarget's namespace, name prefix and
+	// suffix, are merged with its labels and annotations and their patches
+	// are applied after the InitTarget's patches.
+	// +optional
+	Transformations *Transformations `json:"transformations,omitempty"`
 }
 
 type TemplateInitSource struct {
+	// Name is the name of the InitTemplate in the InitTarget's workspace.
+	// +kubebuilder:validation:MinLength=1
 	Name string `json:"name"`
 }
 
+// Transformations modify the objects rendered by a source before they are
+// applied. They are applied in the following order: the default namespace,
+// common labels and annotations, patches and finally the name prefix and
+// suffix.
+type Transformations struct {
+	// Namespace is set on all namespaced objects that do not specify a
+	// namespace. Whether an object is namespaced is determined by the APIs
+	// available in the workspace or, for custom resources, by their CRD if it
+	// is provided by the same source.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Labels are added to all objects, replacing existing labels with the
+	// same keys.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to all objects, replacing existing annotations
+	// with the same keys.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// NamePrefix is prepended to the names of all objects, except for
+	// Namespaces and CustomResourceDefinitions. References between objects
+	// are not updated.
+	// +optional
+	NamePrefix string `json:"namePrefix,omitempty"`
+
+	// NameSuffix is appended to the names of all objects, except for
+	// Namespaces and CustomResourceDefinitions. References between objects
+	// are not updated.
+	// +optional
+	NameSuffix string `json:"nameSuffix,omitempty"`
+
+	// Patches are JSON patches that are applied to the matching objects.
+	// +optional
+	Patches []ObjectPatch `json:"patches,omitempty"`
+}
+
+// ObjectPatch is a JSON patch for a set of objects.
+type ObjectPatch struct {
+	// Target selects the objects to patch. If not set, all objects are
+	// patched.
+	// +optional
+	Target *ObjectSelector `json:"target,omitempty"`
+
+	// Patch is a JSON patch (RFC 6902), i.e. a list of operations, in JSON or
+	// YAML.
+	// +kubebuilder:validation:MinLength=1
+	Patch string `json:"patch"`
+}
+
+// ObjectSelector selects objects by their type and name. Empty fields match
+// all objects.
+type ObjectSelector struct {
+	// Group is the API group of the objects, without their version.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Kind is the kind of the objects.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name is the name of the objects, as rendered by the source.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace is the namespace of the objects.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 
 // InitTargetList contains a list of InitTargets.
@@ -33,6 +33,8 @@ type InitTemplate struct {
 }
 
 type InitTemplateSpec struct {
+	// Template is a Go template rendering any number of YAML documents.
+	// +kubebuilder:validation:MinLength=1
 	Template string `json:"template"`
 }
 
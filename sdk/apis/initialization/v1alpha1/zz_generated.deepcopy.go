@@ -21,6 +21,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -32,6 +33,11 @@ func (in *InitSource) DeepCopyInto(out *InitSource) {
 		*out = new(TemplateInitSource)
 		**out = **in
 	}
+	if in.Transformations != nil {
+		in, out := &in.Transformations, &out.Transformations
+		*out = new(Transformations)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitSource.
@@ -50,6 +56,7 @@ func (in *InitTarget) DeepCopyInto(out *InitTarget) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitTarget.
@@ -106,6 +113,16 @@ func (in *InitTargetList) DeepCopyObject() runtime.Object {
 func (in *InitTargetSpec) DeepCopyInto(out *InitTargetSpec) {
 	*out = *in
 	out.WorkspaceTypeReference = in.WorkspaceTypeReference
+	if in.WorkspaceTypeReferences != nil {
+		in, out := &in.WorkspaceTypeReferences, &out.WorkspaceTypeReferences
+		*out = make([]WorkspaceTypeReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.WorkspaceTypeSelector != nil {
+		in, out := &in.WorkspaceTypeSelector, &out.WorkspaceTypeSelector
+		*out = new(WorkspaceTypeSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Sources != nil {
 		in, out := &in.Sources, &out.Sources
 		*out = make([]InitSource, len(*in))
@@ -113,6 +130,26 @@ func (in *InitTargetSpec) DeepCopyInto(out *InitTargetSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.KindOrder != nil {
+		in, out := &in.KindOrder, &out.KindOrder
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Transformations != nil {
+		in, out := &in.Transformations, &out.Transformations
+		*out = new(Transformations)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitTargetSpec.
@@ -125,6 +162,28 @@ func (in *InitTargetSpec) DeepCopy() *InitTargetSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitTargetStatus) DeepCopyInto(out *InitTargetStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitTargetStatus.
+func (in *InitTargetStatus) DeepCopy() *InitTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InitTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InitTemplate) DeepCopyInto(out *InitTemplate) {
 	*out = *in
@@ -198,6 +257,76 @@ func (in *InitTemplateSpec) DeepCopy() *InitTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectPatch) DeepCopyInto(out *ObjectPatch) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(ObjectSelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectPatch.
+func (in *ObjectPatch) DeepCopy() *ObjectPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectSelector) DeepCopyInto(out *ObjectSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectSelector.
+func (in *ObjectSelector) DeepCopy() *ObjectSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+	if in.InitialDelay != nil {
+		in, out := &in.InitialDelay, &out.InitialDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxDelay != nil {
+		in, out := &in.MaxDelay, &out.MaxDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxAttempts != nil {
+		in, out := &in.MaxAttempts, &out.MaxAttempts
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Deadline != nil {
+		in, out := &in.Deadline, &out.Deadline
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemplateInitSource) DeepCopyInto(out *TemplateInitSource) {
 	*out = *in
@@ -213,6 +342,42 @@ func (in *TemplateInitSource) DeepCopy() *TemplateInitSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Transformations) DeepCopyInto(out *Transformations) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]ObjectPatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Transformations.
+func (in *Transformations) DeepCopy() *Transformations {
+	if in == nil {
+		return nil
+	}
+	out := new(Transformations)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceTypeReference) DeepCopyInto(out *WorkspaceTypeReference) {
 	*out = *in
@@ -227,3 +392,19 @@ func (in *WorkspaceTypeReference) DeepCopy() *WorkspaceTypeReference {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceTypeSelector) DeepCopyInto(out *WorkspaceTypeSelector) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceTypeSelector.
+func (in *WorkspaceTypeSelector) DeepCopy() *WorkspaceTypeSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceTypeSelector)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -21,7 +21,8 @@ package v1alpha1
 // InitSourceApplyConfiguration represents a declarative configuration of the InitSource type for use
 // with apply.
 type InitSourceApplyConfiguration struct {
-	Template *TemplateInitSourceApplyConfiguration `json:"template,omitempty"`
+	Template        *TemplateInitSourceApplyConfiguration `json:"template,omitempty"`
+	Transformations *TransformationsApplyConfiguration    `json:"transformations,omitempty"`
 }
 
 // InitSourceApplyConfiguration constructs a declarative configuration of the InitSource type for use with
@@ -37,3 +38,11 @@ func (b *InitSourceApplyConfiguration) WithTemplate(value *TemplateInitSourceApp
 	b.Template = value
 	return b
 }
+
+// WithTransformations sets the Transformations field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Transformations field is set to the value of the last call.
+func (b *InitSourceApplyConfiguration) WithTransformations(value *TransformationsApplyConfiguration) *InitSourceApplyConfiguration {
+	b.Transformations = value
+	return b
+}
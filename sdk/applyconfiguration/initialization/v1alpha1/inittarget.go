@@ -29,7 +29,8 @@ import (
 type InitTargetApplyConfiguration struct {
 	v1.TypeMetaApplyConfiguration    `json:",inline"`
 	*v1.ObjectMetaApplyConfiguration `json:"metadata,omitempty"`
-	Spec                             *InitTargetSpecApplyConfiguration `json:"spec,omitempty"`
+	Spec                             *InitTargetSpecApplyConfiguration   `json:"spec,omitempty"`
+	Status                           *InitTargetStatusApplyConfiguration `json:"status,omitempty"`
 }
 
 // InitTarget constructs a declarative configuration of the InitTarget type for use with
@@ -208,6 +209,14 @@ func (b *InitTargetApplyConfiguration) WithSpec(value *InitTargetSpecApplyConfig
 	return b
 }
 
+// WithStatus sets the Status field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Status field is set to the value of the last call.
+func (b *InitTargetApplyConfiguration) WithStatus(value *InitTargetStatusApplyConfiguration) *InitTargetApplyConfiguration {
+	b.Status = value
+	return b
+}
+
 // GetName retrieves the value of the Name field in the declarative configuration.
 func (b *InitTargetApplyConfiguration) GetName() *string {
 	b.ensureObjectMetaApplyConfigurationExists()
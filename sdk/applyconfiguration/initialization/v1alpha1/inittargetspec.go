@@ -18,11 +18,24 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	initializationv1alpha1 "github.com/kcp-dev/init-agent/sdk/apis/initialization/v1alpha1"
+)
+
 // InitTargetSpecApplyConfiguration represents a declarative configuration of the InitTargetSpec type for use
 // with apply.
 type InitTargetSpecApplyConfiguration struct {
-	WorkspaceTypeReference *WorkspaceTypeReferenceApplyConfiguration `json:"workspaceTypeRef,omitempty"`
-	Sources                []InitSourceApplyConfiguration            `json:"sources,omitempty"`
+	WorkspaceTypeReference  *WorkspaceTypeReferenceApplyConfiguration  `json:"workspaceTypeRef,omitempty"`
+	WorkspaceTypeReferences []WorkspaceTypeReferenceApplyConfiguration `json:"workspaceTypeRefs,omitempty"`
+	WorkspaceTypeSelector   *WorkspaceTypeSelectorApplyConfiguration   `json:"workspaceTypeSelector,omitempty"`
+	Sources                 []InitSourceApplyConfiguration             `json:"sources,omitempty"`
+	KindOrder               []string                                   `json:"kindOrder,omitempty"`
+	RetryPolicy             *RetryPolicyApplyConfiguration             `json:"retryPolicy,omitempty"`
+	FailurePolicy           *initializationv1alpha1.FailurePolicy      `json:"failurePolicy,omitempty"`
+	Suspend                 *bool                                      `json:"suspend,omitempty"`
+	Workers                 *int32                                     `json:"workers,omitempty"`
+	Priority                *int32                                     `json:"priority,omitempty"`
+	Transformations         *TransformationsApplyConfiguration         `json:"transformations,omitempty"`
 }
 
 // InitTargetSpecApplyConfiguration constructs a declarative configuration of the InitTargetSpec type for use with
@@ -39,6 +52,27 @@ func (b *InitTargetSpecApplyConfiguration) WithWorkspaceTypeReference(value *Wor
 	return b
 }
 
+// WithWorkspaceTypeReferences adds the given value to the WorkspaceTypeReferences field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the WorkspaceTypeReferences field.
+func (b *InitTargetSpecApplyConfiguration) WithWorkspaceTypeReferences(values ...*WorkspaceTypeReferenceApplyConfiguration) *InitTargetSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithWorkspaceTypeReferences")
+		}
+		b.WorkspaceTypeReferences = append(b.WorkspaceTypeReferences, *values[i])
+	}
+	return b
+}
+
+// WithWorkspaceTypeSelector sets the WorkspaceTypeSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WorkspaceTypeSelector field is set to the value of the last call.
+func (b *InitTargetSpecApplyConfiguration) WithWorkspaceTypeSelector(value *WorkspaceTypeSelectorApplyConfiguration) *InitTargetSpecApplyConfiguration {
+	b.WorkspaceTypeSelector = value
+	return b
+}
+
 // WithSources adds the given value to the Sources field in the declarative configuration
 // and returns the receiver, so that objects can be build by chaining "With" function invocations.
 // If called multiple times, values provided by each call will be appended to the Sources field.
@@ -51,3 +85,61 @@ func (b *InitTargetSpecApplyConfiguration) WithSources(values ...*InitSourceAppl
 	}
 	return b
 }
+
+// WithKindOrder adds the given value to the KindOrder field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the KindOrder field.
+func (b *InitTargetSpecApplyConfiguration) WithKindOrder(values ...string) *InitTargetSpecApplyConfiguration {
+	for i := range values {
+		b.KindOrder = append(b.KindOrder, values[i])
+	}
+	return b
+}
+
+// WithRetryPolicy sets the RetryPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RetryPolicy field is set to the value of the last call.
+func (b *InitTargetSpecApplyConfiguration) WithRetryPolicy(value *RetryPolicyApplyConfiguration) *InitTargetSpecApplyConfiguration {
+	b.RetryPolicy = value
+	return b
+}
+
+// WithFailurePolicy sets the FailurePolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FailurePolicy field is set to the value of the last call.
+func (b *InitTargetSpecApplyConfiguration) WithFailurePolicy(value initializationv1alpha1.FailurePolicy) *InitTargetSpecApplyConfiguration {
+	b.FailurePolicy = &value
+	return b
+}
+
+// WithSuspend sets the Suspend field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Suspend field is set to the value of the last call.
+func (b *InitTargetSpecApplyConfiguration) WithSuspend(value bool) *InitTargetSpecApplyConfiguration {
+	b.Suspend = &value
+	return b
+}
+
+// WithWorkers sets the Workers field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Workers field is set to the value of the last call.
+func (b *InitTargetSpecApplyConfiguration) WithWorkers(value int32) *InitTargetSpecApplyConfiguration {
+	b.Workers = &value
+	return b
+}
+
+// WithPriority sets the Priority field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Priority field is set to the value of the last call.
+func (b *InitTargetSpecApplyConfiguration) WithPriority(value int32) *InitTargetSpecApplyConfiguration {
+	b.Priority = &value
+	return b
+}
+
+// WithTransformations sets the Transformations field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Transformations field is set to the value of the last call.
+func (b *InitTargetSpecApplyConfiguration) WithTransformations(value *TransformationsApplyConfiguration) *InitTargetSpecApplyConfiguration {
+	b.Transformations = value
+	return b
+}
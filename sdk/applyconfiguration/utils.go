@@ -39,14 +39,26 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &initializationv1alpha1.InitTargetApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("InitTargetSpec"):
 		return &initializationv1alpha1.InitTargetSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("InitTargetStatus"):
+		return &initializationv1alpha1.InitTargetStatusApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("InitTemplate"):
 		return &initializationv1alpha1.InitTemplateApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("InitTemplateSpec"):
 		return &initializationv1alpha1.InitTemplateSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ObjectPatch"):
+		return &initializationv1alpha1.ObjectPatchApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ObjectSelector"):
+		return &initializationv1alpha1.ObjectSelectorApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("RetryPolicy"):
+		return &initializationv1alpha1.RetryPolicyApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("TemplateInitSource"):
 		return &initializationv1alpha1.TemplateInitSourceApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Transformations"):
+		return &initializationv1alpha1.TransformationsApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkspaceTypeReference"):
 		return &initializationv1alpha1.WorkspaceTypeReferenceApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkspaceTypeSelector"):
+		return &initializationv1alpha1.WorkspaceTypeSelectorApplyConfiguration{}
 
 	}
 	return nil
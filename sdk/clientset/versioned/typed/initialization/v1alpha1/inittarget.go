@@ -40,6 +40,8 @@ type InitTargetsGetter interface {
 type InitTargetInterface interface {
 	Create(ctx context.Context, initTarget *initializationv1alpha1.InitTarget, opts v1.CreateOptions) (*initializationv1alpha1.InitTarget, error)
 	Update(ctx context.Context, initTarget *initializationv1alpha1.InitTarget, opts v1.UpdateOptions) (*initializationv1alpha1.InitTarget, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, initTarget *initializationv1alpha1.InitTarget, opts v1.UpdateOptions) (*initializationv1alpha1.InitTarget, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
 	Get(ctx context.Context, name string, opts v1.GetOptions) (*initializationv1alpha1.InitTarget, error)
@@ -0,0 +1,409 @@
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crd tests the validation rules and defaults of the generated CRDs
+// the same way the API server applies them, without running one.
+package crd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsvalidation "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/validation"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	apiservervalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+	"sigs.k8s.io/yaml"
+)
+
+const crdDirectory = "../../deploy/crd/kcp.io"
+
+// crd is a CRD with a single version, prepared to validate objects.
+type crd struct {
+	structural *structuralschema.Structural
+	validator  apiservervalidation.SchemaValidator
+	celRules   *cel.Validator
+}
+
+// loadCRD reads a generated CRD and validates it like the API server does when
+// it is created, which includes compiling its CEL rules and estimating their
+// cost.
+func loadCRD(t *testing.T, filename string) *crd {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(crdDirectory, filename))
+	if err != nil {
+		t.Fatalf("Failed to read CRD: %v", err)
+	}
+
+	v1crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := utilyaml.Unmarshal(data, v1crd); err != nil {
+		t.Fatalf("Failed to decode CRD: %v", err)
+	}
+
+	if len(v1crd.Spec.Versions) != 1 {
+		t.Fatalf("Expected CRD to have exactly one version, got %d.", len(v1crd.Spec.Versions))
+	}
+
+	// pretend the CRD has been stored before, as the API server requires
+	v1crd.Status.StoredVersions = []string{v1crd.Spec.Versions[0].Name}
+
+	internal := &apiextensions.CustomResourceDefinition{}
+	if err := apiextensionsv1.Convert_v1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(v1crd, internal, nil); err != nil {
+		t.Fatalf("Failed to convert CRD: %v", err)
+	}
+
+	if errs := apiextensionsvalidation.ValidateCustomResourceDefinition(context.Background(), internal); len(errs) > 0 {
+		t.Fatalf("CRD would be rejected by the API server: %v", errs.ToAggregate())
+	}
+
+	schema := internal.Spec.Validation
+	if schema == nil {
+		schema = internal.Spec.Versions[0].Schema
+	}
+
+	structural, err := structuralschema.NewStructural(schema.OpenAPIV3Schema)
+	if err != nil {
+		t.Fatalf("Failed to create structural schema: %v", err)
+	}
+
+	validator, _, err := apiservervalidation.NewSchemaValidator(schema.OpenAPIV3Schema)
+	if err != nil {
+		t.Fatalf("Failed to create schema validator: %v", err)
+	}
+
+	return &crd{
+		structural: structural,
+		validator:  validator,
+		celRules:   cel.NewValidator(structural, true, celconfig.PerCallLimit),
+	}
+}
+
+// decode turns a YAML manifest into an unstructured object, with integers
+// decoded as int64 like the API server does.
+func decode(t *testing.T, manifest string) map[string]any {
+	t.Helper()
+
+	data, err := yaml.YAMLToJSON([]byte(strings.TrimSpace(manifest)))
+	if err != nil {
+		t.Fatalf("Failed to convert manifest to JSON: %v", err)
+	}
+
+	obj := map[string]any{}
+	if err := utiljson.Unmarshal(data, &obj); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+
+	return obj
+}
+
+// admit defaults and validates the object like the API server does when it is
+// created (oldObj is nil) or updated. It returns the defaulted object and the
+// validation errors.
+func (c *crd) admit(obj, oldObj map[string]any) (map[string]any, field.ErrorList) {
+	defaulting.Default(obj, c.structural)
+
+	var errs field.ErrorList
+	if oldObj == nil {
+		errs = apiservervalidation.ValidateCustomResource(nil, obj, c.validator)
+	} else {
+		defaulting.Default(oldObj, c.structural)
+		errs = apiservervalidation.ValidateCustomResourceUpdate(nil, obj, oldObj, c.validator)
+	}
+
+	// oldObj must be an untyped nil on create, so that transition rules are skipped
+	var old any
+	if oldObj != nil {
+		old = oldObj
+	}
+
+	celErrs, _ := c.celRules.Validate(context.Background(), nil, c.structural, obj, old, celconfig.RuntimeCELCostBudget)
+
+	return obj, append(errs, celErrs...)
+}
+
+const validTarget = `
+apiVersion: initialization.kcp.io/v1alpha1
+kind: InitTarget
+metadata:
+  name: init-my-ws-type
+spec:
+  workspaceTypeRef:
+    path: root:my-org
+    name: my-ws-type
+  sources:
+    - template:
+        name: my-template
+`
+
+func TestInitTargetValidation(t *testing.T) {
+	c := loadCRD(t, "initialization.kcp.io_inittargets.yaml")
+
+	testcases := []struct {
+		name     string
+		old      string
+		manifest string
+		// expected is a substring of the expected error; empty if the object
+		// must be valid.
+		expected string
+	}{
+		{
+			name:     "valid InitTarget",
+			manifest: validTarget,
+		},
+		{
+			name: "WorkspaceTypes selected by a list of references",
+			manifest: `
+spec:
+  workspaceTypeRefs:
+    - path: root:my-org
+      name: my-ws-type
+  sources:
+    - template:
+        name: my-template
+`,
+		},
+		{
+			name: "no WorkspaceTypes",
+			manifest: `
+spec:
+  sources:
+    - template:
+        name: my-template
+`,
+			expected: "one of workspaceTypeRef, workspaceTypeRefs or workspaceTypeSelector must be set",
+		},
+		{
+			name: "empty list of WorkspaceType references",
+			manifest: `
+spec:
+  workspaceTypeRefs: []
+  sources:
+    - template:
+        name: my-template
+`,
+			expected: "one of workspaceTypeRef, workspaceTypeRefs or workspaceTypeSelector must be set",
+		},
+		{
+			name: "source without a template",
+			manifest: `
+spec:
+  workspaceTypeRef:
+    name: my-ws-type
+  sources:
+    - {}
+`,
+			expected: "exactly one source must be configured",
+		},
+		{
+			name: "no sources",
+			manifest: `
+spec:
+  workspaceTypeRef:
+    name: my-ws-type
+  sources: []
+`,
+			expected: "spec.sources",
+		},
+		{
+			name: "invalid WorkspaceType name",
+			manifest: `
+spec:
+  workspaceTypeRef:
+    name: My_WS_Type
+  sources:
+    - template:
+        name: my-template
+`,
+			expected: "spec.workspaceTypeRef.name",
+		},
+		{
+			name: "invalid WorkspaceType path",
+			manifest: `
+spec:
+  workspaceTypeRef:
+    path: root::org
+    name: my-ws-type
+  sources:
+    - template:
+        name: my-template
+`,
+			expected: "spec.workspaceTypeRef.path",
+		},
+		{
+			name: "empty template name",
+			manifest: `
+spec:
+  workspaceTypeRef:
+    name: my-ws-type
+  sources:
+    - template:
+        name: ""
+`,
+			expected: "spec.sources[0].template.name",
+		},
+		{
+			name: "unknown failure policy",
+			manifest: `
+spec:
+  workspaceTypeRef:
+    name: my-ws-type
+  failurePolicy: Ignore
+  sources:
+    - template:
+        name: my-template
+`,
+			expected: "spec.failurePolicy",
+		},
+		{
+			name: "priority out of range",
+			manifest: `
+spec:
+  workspaceTypeRef:
+    name: my-ws-type
+  priority: 101
+  sources:
+    - template:
+        name: my-template
+`,
+			expected: "spec.priority",
+		},
+		{
+			name: "changed workspaceTypeRef",
+			old:  validTarget,
+			manifest: `
+spec:
+  workspaceTypeRef:
+    path: root:my-org
+    name: another-ws-type
+  sources:
+    - template:
+        name: my-template
+`,
+			expected: "workspaceTypeRef is immutable",
+		},
+		{
+			name: "removed workspaceTypeRef",
+			old:  validTarget,
+			manifest: `
+spec:
+  workspaceTypeRefs:
+    - path: root:my-org
+      name: my-ws-type
+  sources:
+    - template:
+        name: my-template
+`,
+			expected: "workspaceTypeRef cannot be added or removed",
+		},
+		{
+			name: "changed sources",
+			old:  validTarget,
+			manifest: `
+spec:
+  workspaceTypeRef:
+    path: root:my-org
+    name: my-ws-type
+  sources:
+    - template:
+        name: another-template
+`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var old map[string]any
+			if tc.old != "" {
+				old = decode(t, tc.old)
+			}
+
+			_, errs := c.admit(decode(t, tc.manifest), old)
+
+			if tc.expected == "" {
+				if len(errs) > 0 {
+					t.Fatalf("Expected InitTarget to be valid, got %v", errs.ToAggregate())
+				}
+
+				return
+			}
+
+			if len(errs) == 0 {
+				t.Fatalf("Expected InitTarget to be rejected with %q, but it was accepted.", tc.expected)
+			}
+
+			if msg := errs.ToAggregate().Error(); !strings.Contains(msg, tc.expected) {
+				t.Fatalf("Expected InitTarget to be rejected with %q, got %v", tc.expected, msg)
+			}
+		})
+	}
+}
+
+func TestInitTargetDefaults(t *testing.T) {
+	c := loadCRD(t, "initialization.kcp.io_inittargets.yaml")
+
+	obj, errs := c.admit(decode(t, validTarget), nil)
+	if len(errs) > 0 {
+		t.Fatalf("Expected InitTarget to be valid, got %v", errs.ToAggregate())
+	}
+
+	spec := obj["spec"].(map[string]any)
+	if policy := spec["failurePolicy"]; policy != "Block" {
+		t.Errorf("Expected failurePolicy to default to Block, got %v.", policy)
+	}
+
+	// explicitly configured values must not be overwritten
+	obj, _ = c.admit(decode(t, validTarget+"  failurePolicy: Fail\n"), nil)
+
+	spec = obj["spec"].(map[string]any)
+	if policy := spec["failurePolicy"]; policy != "Fail" {
+		t.Errorf("Expected failurePolicy to remain Fail, got %v.", policy)
+	}
+}
+
+func TestInitTemplateValidation(t *testing.T) {
+	c := loadCRD(t, "initialization.kcp.io_inittemplates.yaml")
+
+	_, errs := c.admit(decode(t, `
+spec:
+  template: |
+    apiVersion: v1
+    kind: Namespace
+    metadata:
+      name: foo
+`), nil)
+	if len(errs) > 0 {
+		t.Fatalf("Expected InitTemplate to be valid, got %v", errs.ToAggregate())
+	}
+
+	_, errs = c.admit(decode(t, `
+spec:
+  template: ""
+`), nil)
+	if len(errs) == 0 || !strings.Contains(errs.ToAggregate().Error(), "spec.template") {
+		t.Fatalf("Expected InitTemplate with an empty template to be rejected, got %v", errs.ToAggregate())
+	}
+}
@@ -0,0 +1,142 @@
+//go:build e2e
+
+/*
+Copyright 2026 The kcp Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterinit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kcp-dev/init-agent/test/utils"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrlruntime "sigs.k8s.io/controller-runtime"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestLeasesAreReleasedOnShutdown(t *testing.T) {
+	const initAgentWorkspace = "my-init-agent-shutdown"
+
+	ctx := t.Context()
+	ctrlruntime.SetLogger(logr.Discard())
+
+	kcpClusterClient := utils.GetKcpAdminClusterClient(t)
+	rootClient := kcpClusterClient.Cluster(rootCluster)
+
+	t.Log("Creating init-agent workspace…")
+	initAgentCluster := utils.CreateAndWaitForWorkspace(t, ctx, rootClient, initAgentWorkspace)
+
+	initAgentClient := kcpClusterClient.Cluster(initAgentCluster.Path())
+	utils.GrantWorkspaceAccess(t, ctx, initAgentClient, utils.Subject(), rbacv1.PolicyRule{
+		APIGroups: []string{"initialization.kcp.io"},
+		Resources: []string{"inittargets", "inittemplates"},
+		Verbs:     []string{"get", "list", "watch", "update", "patch"},
+	}, rbacv1.PolicyRule{
+		APIGroups: []string{"initialization.kcp.io"},
+		Resources: []string{"inittargets/status"},
+		Verbs:     []string{"get", "update", "patch"},
+	}, rbacv1.PolicyRule{
+		APIGroups: []string{"coordination.k8s.io"},
+		Resources: []string{"leases"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "delete"},
+	}, rbacv1.PolicyRule{
+		APIGroups: []string{""},
+		Resources: []string{"events"},
+		Verbs:     []string{"create", "patch"},
+	})
+
+	t.Log("Installing CRDs…")
+	utils.ApplyCRD(t, ctx, initAgentClient, "deploy/crd/kcp.io/initialization.kcp.io_inittargets.yaml")
+	utils.ApplyCRD(t, ctx, initAgentClient, "deploy/crd/kcp.io/initialization.kcp.io_inittemplates.yaml")
+
+	agentKubeconfig := utils.CreateKcpAgentKubeconfig(t, "")
+	configWorkspace := rootCluster.Join(initAgentWorkspace).String()
+
+	t.Run("leader election", func(t *testing.T) {
+		leaseName := types.NamespacedName{Namespace: "default", Name: "kcp-init-agent"}
+
+		stop := utils.RunAgent(ctx, t, agentKubeconfig, configWorkspace, "",
+			"--enable-leader-election=true",
+			"--leader-election-id", leaseName.Name,
+		)
+
+		t.Log("Waiting for the agent to become the leader…")
+		waitForLease(t, ctx, initAgentClient, leaseName, func(lease *coordinationv1.Lease) bool {
+			return lease != nil && lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != ""
+		})
+
+		t.Log("Stopping the agent…")
+		stop()
+
+		// The Lease is released, not deleted, so that a new leader can take
+		// over without waiting for it to expire.
+		waitForLease(t, ctx, initAgentClient, leaseName, func(lease *coordinationv1.Lease) bool {
+			return lease != nil && (lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "")
+		})
+	})
+
+	t.Run("sharding", func(t *testing.T) {
+		leaseName := types.NamespacedName{Namespace: "default", Name: "kcp-init-agent-e2e-replica"}
+
+		stop := utils.RunAgent(ctx, t, agentKubeconfig, configWorkspace, "",
+			"--enable-sharding",
+			"--shard-identity", "e2e-replica",
+		)
+
+		t.Log("Waiting for the agent to join the shard group…")
+		waitForLease(t, ctx, initAgentClient, leaseName, func(lease *coordinationv1.Lease) bool {
+			return lease != nil
+		})
+
+		t.Log("Stopping the agent…")
+		stop()
+
+		waitForLease(t, ctx, initAgentClient, leaseName, func(lease *coordinationv1.Lease) bool {
+			return lease == nil
+		})
+	})
+}
+
+// waitForLease waits until the condition is met for the Lease; the condition
+// is called with nil if the Lease does not exist.
+func waitForLease(t *testing.T, ctx context.Context, client ctrlruntimeclient.Client, name types.NamespacedName, condition func(*coordinationv1.Lease) bool) {
+	t.Helper()
+
+	err := wait.PollUntilContextTimeout(ctx, 500*time.Millisecond, 30*time.Second, true, func(ctx context.Context) (bool, error) {
+		lease := &coordinationv1.Lease{}
+		err := client.Get(ctx, name, lease)
+		if apierrors.IsNotFound(err) {
+			return condition(nil), nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		return condition(lease), nil
+	})
+	if err != nil {
+		t.Fatalf("Lease %s did not reach the expected state: %v", name, err)
+	}
+}
@@ -24,7 +24,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func requiredEnv(t *testing.T, name string) string {
@@ -70,6 +72,7 @@ func RunAgent(
 	kcpKubeconfig string,
 	configWorkspace string,
 	labelSelector string,
+	extraArgs ...string,
 ) context.CancelFunc {
 	t.Helper()
 
@@ -89,6 +92,9 @@ func RunAgent(
 		args = append(args, "--init-target-selector", labelSelector)
 	}
 
+	// later flags take precedence, so the defaults above can be overridden
+	args = append(args, extraArgs...)
+
 	logFile := filepath.Join(ArtifactsDirectory(t), uniqueLogfile(t, ""))
 	log, err := os.Create(logFile)
 	if err != nil {
@@ -101,6 +107,13 @@ func RunAgent(
 	cmd.Stdout = log
 	cmd.Stderr = log
 
+	// stop the agent like Kubernetes would stop its pod, so that it can shut
+	// down cleanly, and only kill it if it does not stop in time
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = 30 * time.Second
+
 	if err := cmd.Start(); err != nil {
 		t.Fatalf("Failed to start init-agent: %v", err)
 	}
@@ -31,6 +31,7 @@ import (
 	kcpapisv1alpha1 "github.com/kcp-dev/sdk/apis/apis/v1alpha1"
 	kcptenancyv1alpha1 "github.com/kcp-dev/sdk/apis/tenancy/v1alpha1"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -62,6 +63,7 @@ func newScheme(t *testing.T) *runtime.Scheme {
 	sc := runtime.NewScheme()
 	must(t, scheme.AddToScheme(sc))
 	must(t, corev1.AddToScheme(sc))
+	must(t, coordinationv1.AddToScheme(sc))
 	must(t, rbacv1.AddToScheme(sc))
 	must(t, kcptenancyv1alpha1.AddToScheme(sc))
 	must(t, kcpapisv1alpha1.AddToScheme(sc))